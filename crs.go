@@ -0,0 +1,85 @@
+package goshp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CRS describes the coordinate reference system of a shapefile: its
+// projection definition as ESRI WKT (as found in a .prj sidecar), and
+// the EPSG code it corresponds to, if known.
+type CRS struct {
+	WKT  string
+	EPSG int // 0 if unknown
+}
+
+// epsgWKT maps common EPSG codes to their canonical ESRI WKT, letting
+// callers write a valid .prj sidecar from just the numeric code.
+var epsgWKT = map[int]string{
+	4326:  `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`,
+	3857:  `PROJCS["WGS_1984_Web_Mercator_Auxiliary_Sphere",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Mercator_Auxiliary_Sphere"],PARAMETER["False_Easting",0.0],PARAMETER["False_Northing",0.0],PARAMETER["Central_Meridian",0.0],PARAMETER["Standard_Parallel_1",0.0],UNIT["Meter",1.0]]`,
+	25832: `PROJCS["ETRS_1989_UTM_Zone_32N",GEOGCS["GCS_ETRS_1989",DATUM["D_ETRS_1989",SPHEROID["GRS_1980",6378137.0,298.257222101]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Transverse_Mercator"],PARAMETER["False_Easting",500000.0],PARAMETER["False_Northing",0.0],PARAMETER["Central_Meridian",9.0],PARAMETER["Scale_Factor",0.9996],PARAMETER["Latitude_Of_Origin",0.0],UNIT["Meter",1.0]]`,
+	25833: `PROJCS["ETRS_1989_UTM_Zone_33N",GEOGCS["GCS_ETRS_1989",DATUM["D_ETRS_1989",SPHEROID["GRS_1980",6378137.0,298.257222101]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Transverse_Mercator"],PARAMETER["False_Easting",500000.0],PARAMETER["False_Northing",0.0],PARAMETER["Central_Meridian",15.0],PARAMETER["Scale_Factor",0.9996],PARAMETER["Latitude_Of_Origin",0.0],UNIT["Meter",1.0]]`,
+	31287: `PROJCS["MGI_Austria_Lambert",GEOGCS["GCS_MGI",DATUM["D_MGI",SPHEROID["Bessel_1841",6377397.155,299.1528128]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]],PROJECTION["Lambert_Conformal_Conic"],PARAMETER["False_Easting",400000.0],PARAMETER["False_Northing",400000.0],PARAMETER["Central_Meridian",13.333333333333334],PARAMETER["Standard_Parallel_1",46.0],PARAMETER["Standard_Parallel_2",49.0],PARAMETER["Latitude_Of_Origin",47.5],UNIT["Meter",1.0]]`,
+}
+
+// CRSFromEPSG returns the CRS for a known EPSG code, or an error if the
+// code is not in the registry.
+func CRSFromEPSG(epsg int) (CRS, error) {
+	wkt, ok := epsgWKT[epsg]
+	if !ok {
+		return CRS{}, fmt.Errorf("goshp: no WKT registered for EPSG:%d", epsg)
+	}
+	return CRS{WKT: wkt, EPSG: epsg}, nil
+}
+
+func epsgForWKT(wkt string) int {
+	for epsg, known := range epsgWKT {
+		if known == wkt {
+			return epsg
+		}
+	}
+	return 0
+}
+
+func readPrj(filename string) (CRS, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return CRS{}, err
+	}
+	wkt := strings.TrimSpace(string(b))
+	return CRS{WKT: wkt, EPSG: epsgForWKT(wkt)}, nil
+}
+
+func writePrj(filename string, crs CRS) error {
+	wkt := crs.WKT
+	if wkt == "" && crs.EPSG != 0 {
+		var err error
+		wkt, err = lookupWKT(crs.EPSG)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filename, []byte(wkt), 0644)
+}
+
+func lookupWKT(epsg int) (string, error) {
+	crs, err := CRSFromEPSG(epsg)
+	if err != nil {
+		return "", err
+	}
+	return crs.WKT, nil
+}
+
+// CRS returns the coordinate reference system read from the .prj
+// sidecar, if one was present alongside the shapefile.
+func (r *Reader) CRS() CRS {
+	return r.crs
+}
+
+func prjFilename(shpName string) string {
+	base := strings.TrimSuffix(shpName, filepath.Ext(shpName))
+	return base + ".prj"
+}
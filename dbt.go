@@ -0,0 +1,118 @@
+package goshp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// dbtBlockSize is the dBase III+ memo block size.
+const dbtBlockSize = 512
+
+// dbtTerminator marks the end of a memo's text within its block(s).
+var dbtTerminator = []byte{0x1A, 0x1A}
+
+// DBTWriter writes memo values to a .dbt block file, returning the block
+// number to store in the paired DBF memo field.
+type DBTWriter struct {
+	f         *os.File
+	nextBlock uint32
+}
+
+// CreateDBT creates a new .dbt file at filename for writing memo values.
+func CreateDBT(filename string) (*DBTWriter, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &DBTWriter{f: f, nextBlock: 1}
+	if err := w.writeHeader(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteMemo appends text as one or more new memo blocks and returns the
+// block number to store in the DBF record's memo field.
+func (w *DBTWriter) WriteMemo(text string) (uint32, error) {
+	block := w.nextBlock
+
+	payload := append([]byte(text), dbtTerminator...)
+	numBlocks := (len(payload) + dbtBlockSize - 1) / dbtBlockSize
+	padded := make([]byte, numBlocks*dbtBlockSize)
+	copy(padded, payload)
+
+	if _, err := w.f.WriteAt(padded, int64(block)*dbtBlockSize); err != nil {
+		return 0, err
+	}
+
+	w.nextBlock += uint32(numBlocks)
+	if err := w.writeHeader(); err != nil {
+		return 0, err
+	}
+	return block, nil
+}
+
+func (w *DBTWriter) writeHeader() error {
+	var header [dbtBlockSize]byte
+	binary.LittleEndian.PutUint32(header[0:4], w.nextBlock)
+	_, err := w.f.WriteAt(header[:], 0)
+	return err
+}
+
+// Close closes the underlying .dbt file.
+func (w *DBTWriter) Close() error {
+	return w.f.Close()
+}
+
+// DBTReader reads memo values back out of a .dbt block file.
+type DBTReader struct {
+	f *os.File
+}
+
+// OpenDBT opens the .dbt file at filename for reading.
+func OpenDBT(filename string) (*DBTReader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &DBTReader{f: f}, nil
+}
+
+// ReadMemo returns the text stored at block. A block of 0, meaning the
+// DBF record has no memo set, returns an empty string.
+func (r *DBTReader) ReadMemo(block uint32) (string, error) {
+	if block == 0 {
+		return "", nil
+	}
+
+	var text bytes.Buffer
+	chunk := make([]byte, dbtBlockSize)
+	off := int64(block) * dbtBlockSize
+	for {
+		n, err := r.f.ReadAt(chunk, off)
+		if n == 0 && err != nil {
+			return "", fmt.Errorf("goshp: reading dbt block %d: %w", block, err)
+		}
+		text.Write(chunk[:n])
+		// Search everything read so far: the terminator's two bytes can
+		// straddle a block boundary.
+		if i := bytes.Index(text.Bytes(), dbtTerminator); i >= 0 {
+			text.Truncate(i)
+			break
+		}
+		off += int64(n)
+		if n < dbtBlockSize {
+			break
+		}
+	}
+	return text.String(), nil
+}
+
+// Close closes the underlying .dbt file.
+func (r *DBTReader) Close() error {
+	return r.f.Close()
+}
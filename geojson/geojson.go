@@ -0,0 +1,597 @@
+// Package geojson converts between a shapefile's shapes and DBF
+// attributes and RFC 7946 GeoJSON.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	goshp "github.com/davidrlarochelle/go-shp"
+)
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string                 `json:"type"`
+	Geometry   geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+var dateRE = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Encode writes every shape and DBF record in r as a GeoJSON
+// FeatureCollection.
+func Encode(w io.Writer, r *goshp.Reader) error {
+	fc := featureCollection{Type: "FeatureCollection"}
+
+	fields := r.Fields()
+	for r.Next() {
+		_, shape := r.Shape()
+		geom, err := shapeToGeometry(shape)
+		if err != nil {
+			return err
+		}
+
+		props := make(map[string]interface{}, len(fields))
+		for i, f := range fields {
+			props[fieldName(f)] = attributeToJSON(f, r.Attribute(i))
+		}
+
+		fc.Features = append(fc.Features, feature{
+			Type:       "Feature",
+			Geometry:   geom,
+			Properties: props,
+		})
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(fc)
+}
+
+// fieldName trims the NUL padding Field.String leaves in from the
+// fixed-size on-disk Name array.
+func fieldName(f goshp.Field) string {
+	return strings.TrimRight(f.String(), "\x00")
+}
+
+func attributeToJSON(f goshp.Field, value string) interface{} {
+	switch f.Fieldtype {
+	case 'N':
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+		return value
+	case 'F':
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+		return value
+	case 'D':
+		if len(value) == 8 {
+			return fmt.Sprintf("%s-%s-%s", value[0:4], value[4:6], value[6:8])
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// attributeFromJSON is the inverse of attributeToJSON: it converts a
+// decoded GeoJSON property back into the string form the writer
+// expects, notably turning a D field's YYYY-MM-DD back into YYYYMMDD.
+func attributeFromJSON(f goshp.Field, value interface{}) interface{} {
+	if f.Fieldtype == 'D' {
+		if s, ok := value.(string); ok && dateRE.MatchString(s) {
+			return s[0:4] + s[5:7] + s[8:10]
+		}
+	}
+	return value
+}
+
+func shapeToGeometry(s goshp.Shape) (geometry, error) {
+	switch g := s.(type) {
+	case *goshp.Null:
+		return geometry{}, nil
+	case *goshp.Point:
+		return geometry{Type: "Point", Coordinates: []float64{g.X, g.Y}}, nil
+	case *goshp.PointZ:
+		return geometry{Type: "Point", Coordinates: []float64{g.X, g.Y, g.Z, g.M}}, nil
+	case *goshp.PointM:
+		return geometry{Type: "Point", Coordinates: []float64{g.X, g.Y, 0, g.M}}, nil
+	case *goshp.PolyLine:
+		return polyLineGeometry(g.Parts, g.NumParts, g.NumPoints, g.Points, nil, nil), nil
+	case *goshp.PolyLineZ:
+		return polyLineGeometry(g.Parts, g.NumParts, g.NumPoints, g.Points, g.Zarray, g.Marray), nil
+	case *goshp.PolyLineM:
+		return polyLineGeometry(g.Parts, g.NumParts, g.NumPoints, g.Points, nil, g.Marray), nil
+	case *goshp.Polygon:
+		outer, holes := g.Rings()
+		return polygonGeometry(outer, holes, nil, nil, nil, nil), nil
+	case *goshp.PolygonZ:
+		outer, holes, z, m, holesZ, holesM := polygonZMRings(g.Parts, g.NumPoints, g.Points, g.Zarray, g.Marray)
+		return polygonGeometry(outer, holes, z, m, holesZ, holesM), nil
+	case *goshp.PolygonM:
+		outer, holes, _, m, _, holesM := polygonZMRings(g.Parts, g.NumPoints, g.Points, nil, g.Marray)
+		return polygonGeometry(outer, holes, nil, m, nil, holesM), nil
+	default:
+		return geometry{}, fmt.Errorf("geojson: unsupported shape type %T", s)
+	}
+}
+
+func partRange(parts []int32, numPoints int32, i int) (start, end int32) {
+	start = parts[i]
+	if i == len(parts)-1 {
+		end = numPoints
+	} else {
+		end = parts[i+1]
+	}
+	return
+}
+
+// pointCoords returns the GeoJSON coordinate array for points, appending
+// a Z and/or M element when the caller supplies those parallel arrays.
+func pointCoords(points []goshp.Point, z, m []float64) [][]float64 {
+	coords := make([][]float64, len(points))
+	for i, p := range points {
+		c := []float64{p.X, p.Y}
+		if z != nil {
+			c = append(c, z[i])
+		}
+		if m != nil {
+			if z == nil {
+				c = append(c, 0)
+			}
+			c = append(c, m[i])
+		}
+		coords[i] = c
+	}
+	return coords
+}
+
+func polyLineGeometry(parts []int32, numParts, numPoints int32, points []goshp.Point, z, m []float64) geometry {
+	if numParts <= 1 {
+		return geometry{Type: "LineString", Coordinates: pointCoords(points, z, m)}
+	}
+
+	lines := make([][][]float64, numParts)
+	for i := 0; i < int(numParts); i++ {
+		start, end := partRange(parts, numPoints, i)
+		lines[i] = pointCoords(points[start:end], slice(z, start, end), slice(m, start, end))
+	}
+	return geometry{Type: "MultiLineString", Coordinates: lines}
+}
+
+// slice returns s[start:end], or nil if s itself is nil.
+func slice(s []float64, start, end int32) []float64 {
+	if s == nil {
+		return nil
+	}
+	return s[start:end]
+}
+
+// polygonZMRings classifies a PolygonZ/PolygonM's parts into outer rings
+// and their holes the same way goshp.Polygon.Rings does, additionally
+// carrying each ring's parallel Z/M coordinates (either may be nil).
+func polygonZMRings(parts []int32, numPoints int32, points []goshp.Point, z, m []float64) (outer [][]goshp.Point, holes [][][]goshp.Point, outerZ, outerM [][]float64, holesZ, holesM [][][]float64) {
+	type ring struct {
+		points []goshp.Point
+		z, m   []float64
+		area   float64
+	}
+	rings := make([]ring, len(parts))
+	for i := range parts {
+		start, end := partRange(parts, numPoints, i)
+		rings[i] = ring{points: points[start:end], z: slice(z, start, end), m: slice(m, start, end), area: ringArea2D(points[start:end])}
+	}
+
+	var outers []ring
+	for _, r := range rings {
+		if r.area < 0 {
+			outers = append(outers, r)
+		}
+	}
+	noNegativeRings := len(outers) == 0
+	if noNegativeRings {
+		outers = rings
+	}
+
+	outer = make([][]goshp.Point, len(outers))
+	outerZ = make([][]float64, len(outers))
+	outerM = make([][]float64, len(outers))
+	holes = make([][][]goshp.Point, len(outers))
+	holesZ = make([][][]float64, len(outers))
+	holesM = make([][][]float64, len(outers))
+	for i, o := range outers {
+		outer[i] = o.points
+		outerZ[i] = o.z
+		outerM[i] = o.m
+	}
+	if noNegativeRings {
+		return
+	}
+
+	for _, r := range rings {
+		if r.area < 0 || len(r.points) == 0 {
+			continue
+		}
+		best := -1
+		first := r.points[0]
+		for i, o := range outers {
+			box := goshp.BBoxFromPoints(o.points)
+			if first.X < box.MinX || first.X > box.MaxX || first.Y < box.MinY || first.Y > box.MaxY {
+				continue
+			}
+			if best == -1 || absFloat(outers[i].area) < absFloat(outers[best].area) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			holes[best] = append(holes[best], r.points)
+			holesZ[best] = append(holesZ[best], r.z)
+			holesM[best] = append(holesM[best], r.m)
+		}
+	}
+	return
+}
+
+// ringArea2D is the shoelace sum used to classify a ring's winding,
+// ignoring any Z/M coordinates, mirroring goshp's own ringArea.
+func ringArea2D(points []goshp.Point) float64 {
+	var sum float64
+	for i := 0; i < len(points); i++ {
+		j := (i + 1) % len(points)
+		sum += points[i].X*points[j].Y - points[j].X*points[i].Y
+	}
+	return 0.5 * sum
+}
+
+func absFloat(a float64) float64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func polygonGeometry(outers [][]goshp.Point, holes [][][]goshp.Point, outerZ, outerM [][]float64, holesZ, holesM [][][]float64) geometry {
+	if len(outers) <= 1 {
+		rings := [][][]float64{}
+		if len(outers) == 1 {
+			rings = append(rings, pointCoords(outers[0], at(outerZ, 0), at(outerM, 0)))
+			for i, h := range holes[0] {
+				rings = append(rings, pointCoords(h, at2(holesZ, 0, i), at2(holesM, 0, i)))
+			}
+		}
+		return geometry{Type: "Polygon", Coordinates: rings}
+	}
+
+	polys := make([][][][]float64, len(outers))
+	for i, o := range outers {
+		rings := [][][]float64{pointCoords(o, at(outerZ, i), at(outerM, i))}
+		for j, h := range holes[i] {
+			rings = append(rings, pointCoords(h, at2(holesZ, i, j), at2(holesM, i, j)))
+		}
+		polys[i] = rings
+	}
+	return geometry{Type: "MultiPolygon", Coordinates: polys}
+}
+
+// at returns s[i], or nil if s is nil.
+func at(s [][]float64, i int) []float64 {
+	if s == nil {
+		return nil
+	}
+	return s[i]
+}
+
+// at2 returns s[i][j], or nil if s is nil.
+func at2(s [][][]float64, i, j int) []float64 {
+	if s == nil {
+		return nil
+	}
+	return s[i][j]
+}
+
+// Decode reads a GeoJSON FeatureCollection from r and returns the
+// shapes and DBF-ready field/record data needed to write a shapefile.
+func Decode(r io.Reader) ([]goshp.Shape, []goshp.Field, [][]interface{}, error) {
+	var fc featureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, nil, nil, err
+	}
+
+	shapes := make([]goshp.Shape, len(fc.Features))
+	fieldNames, fieldOrder := collectFields(fc.Features)
+	fields := make([]goshp.Field, len(fieldOrder))
+	for i, name := range fieldOrder {
+		fields[i] = fieldNames[name]
+	}
+
+	records := make([][]interface{}, len(fc.Features))
+	for i, f := range fc.Features {
+		shape, err := geometryToShape(f.Geometry)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		shapes[i] = shape
+
+		row := make([]interface{}, len(fieldOrder))
+		for j, name := range fieldOrder {
+			row[j] = attributeFromJSON(fields[j], f.Properties[name])
+		}
+		records[i] = row
+	}
+
+	return shapes, fields, records, nil
+}
+
+// collectFields gathers every property name used across features into a
+// DBF field, in sorted order. Sorting (rather than first-seen order,
+// which Go's randomized map iteration can't give us without re-parsing
+// the raw JSON token-by-token) is what makes the resulting column layout
+// reproducible across runs.
+func collectFields(features []feature) (map[string]goshp.Field, []string) {
+	fields := make(map[string]goshp.Field)
+	for _, f := range features {
+		for name, value := range f.Properties {
+			if _, ok := fields[name]; ok {
+				continue
+			}
+			fields[name] = inferField(name, value)
+		}
+	}
+
+	order := make([]string, 0, len(fields))
+	for name := range fields {
+		order = append(order, name)
+	}
+	sort.Strings(order)
+	return fields, order
+}
+
+func inferField(name string, value interface{}) goshp.Field {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return goshp.NumberField(name, 18)
+		}
+		return goshp.FloatField(name, 20, 8)
+	case string:
+		if dateRE.MatchString(v) {
+			return goshp.DateField(name)
+		}
+		return goshp.StringField(name, 254)
+	default:
+		return goshp.StringField(name, 254)
+	}
+}
+
+func geometryToShape(g geometry) (goshp.Shape, error) {
+	switch g.Type {
+	case "Point":
+		coords, ok := g.Coordinates.([]interface{})
+		if !ok || len(coords) < 2 {
+			return nil, fmt.Errorf("geojson: malformed Point coordinates")
+		}
+		c, hasZ := parseCoord(coords)
+		if hasZ {
+			return &goshp.PointZ{X: c.X, Y: c.Y, Z: c.Z, M: c.M}, nil
+		}
+		return &goshp.Point{X: c.X, Y: c.Y}, nil
+	case "LineString":
+		coords, _ := g.Coordinates.([]interface{})
+		return buildPolyLine([][]interface{}{coords}), nil
+	case "MultiLineString":
+		parts, _ := g.Coordinates.([]interface{})
+		partsOfCoords := make([][]interface{}, len(parts))
+		for i, part := range parts {
+			partsOfCoords[i], _ = part.([]interface{})
+		}
+		return buildPolyLine(partsOfCoords), nil
+	case "Polygon":
+		rings, _ := g.Coordinates.([]interface{})
+		outer, holes, hasZ := polygonRings(rings)
+		return buildPolygon([][]coord{outer}, [][][]coord{holes}, hasZ), nil
+	case "MultiPolygon":
+		polys, _ := g.Coordinates.([]interface{})
+		outers := make([][]coord, len(polys))
+		holes := make([][][]coord, len(polys))
+		hasZ := false
+		for i, poly := range polys {
+			rings, _ := poly.([]interface{})
+			var ringHasZ bool
+			outers[i], holes[i], ringHasZ = polygonRings(rings)
+			if ringHasZ {
+				hasZ = true
+			}
+		}
+		return buildPolygon(outers, holes, hasZ), nil
+	default:
+		return &goshp.Null{}, nil
+	}
+}
+
+// coord is a parsed GeoJSON coordinate array: X and Y are always
+// present, Z is the optional third element, M the optional fourth (per
+// the RFC 7946 altitude convention and the de facto LRS extension to
+// it).
+type coord struct {
+	X, Y, Z, M float64
+}
+
+// parseCoord parses a single GeoJSON coordinate array. The returned bool
+// reports whether a third (Z) element was present.
+func parseCoord(c interface{}) (coord, bool) {
+	arr, ok := c.([]interface{})
+	if !ok || len(arr) < 2 {
+		return coord{}, false
+	}
+	var out coord
+	out.X, _ = arr[0].(float64)
+	out.Y, _ = arr[1].(float64)
+	hasZ := len(arr) >= 3
+	if hasZ {
+		out.Z, _ = arr[2].(float64)
+	}
+	if len(arr) >= 4 {
+		out.M, _ = arr[3].(float64)
+	}
+	return out, hasZ
+}
+
+// coordsOf parses every coordinate in part. The returned bool reports
+// whether any of them carried a Z element.
+func coordsOf(part []interface{}) ([]coord, bool) {
+	coords := make([]coord, len(part))
+	hasZ := false
+	for i, c := range part {
+		pt, z := parseCoord(c)
+		coords[i] = pt
+		if z {
+			hasZ = true
+		}
+	}
+	return coords, hasZ
+}
+
+// polygonRings splits a GeoJSON Polygon's ring array, where the first
+// ring is the exterior and any remaining rings are holes, into points.
+func polygonRings(rings []interface{}) (outer []coord, holes [][]coord, hasZ bool) {
+	if len(rings) == 0 {
+		return nil, nil, false
+	}
+	outer, hasZ = coordsOf(toCoordArray(rings[0]))
+	for _, r := range rings[1:] {
+		h, z := coordsOf(toCoordArray(r))
+		holes = append(holes, h)
+		if z {
+			hasZ = true
+		}
+	}
+	return outer, holes, hasZ
+}
+
+func toCoordArray(r interface{}) []interface{} {
+	arr, _ := r.([]interface{})
+	return arr
+}
+
+func buildPolyLine(parts [][]interface{}) goshp.Shape {
+	coordParts := make([][]coord, len(parts))
+	hasZ := false
+	for i, part := range parts {
+		var z bool
+		coordParts[i], z = coordsOf(part)
+		if z {
+			hasZ = true
+		}
+	}
+
+	var points []goshp.Point
+	var zarr, marr []float64
+	offsets := make([]int32, len(coordParts))
+	for i, part := range coordParts {
+		offsets[i] = int32(len(points))
+		for _, c := range part {
+			points = append(points, goshp.Point{X: c.X, Y: c.Y})
+			zarr = append(zarr, c.Z)
+			marr = append(marr, c.M)
+		}
+	}
+
+	if !hasZ {
+		return &goshp.PolyLine{
+			Box:       goshp.BBoxFromPoints(points),
+			NumParts:  int32(len(coordParts)),
+			NumPoints: int32(len(points)),
+			Parts:     offsets,
+			Points:    points,
+		}
+	}
+
+	zmin, zmax := minMax(zarr)
+	mmin, mmax := minMax(marr)
+	return &goshp.PolyLineZ{
+		Box:       goshp.BBoxFromPoints(points),
+		NumParts:  int32(len(coordParts)),
+		NumPoints: int32(len(points)),
+		Parts:     offsets,
+		Points:    points,
+		Zmin:      zmin, Zmax: zmax, Zarray: zarr,
+		Mmin: mmin, Mmax: mmax, Marray: marr,
+	}
+}
+
+func buildPolygon(outers [][]coord, holes [][][]coord, hasZ bool) goshp.Shape {
+	if !hasZ {
+		outerPts := make([][]goshp.Point, len(outers))
+		holePts := make([][][]goshp.Point, len(outers))
+		for i, o := range outers {
+			outerPts[i] = toPoints(o)
+			holePts[i] = make([][]goshp.Point, len(holes[i]))
+			for j, h := range holes[i] {
+				holePts[i][j] = toPoints(h)
+			}
+		}
+		p := goshp.NewPolygonFromRings(outerPts, holePts)
+		return &p
+	}
+
+	outerZ := make([][]goshp.PointZ, len(outers))
+	holeZ := make([][][]goshp.PointZ, len(outers))
+	for i, o := range outers {
+		outerZ[i] = toPointZs(o)
+		holeZ[i] = make([][]goshp.PointZ, len(holes[i]))
+		for j, h := range holes[i] {
+			holeZ[i][j] = toPointZs(h)
+		}
+	}
+	p := goshp.NewPolygonZFromRings(outerZ, holeZ)
+	return &p
+}
+
+func toPoints(coords []coord) []goshp.Point {
+	points := make([]goshp.Point, len(coords))
+	for i, c := range coords {
+		points[i] = goshp.Point{X: c.X, Y: c.Y}
+	}
+	return points
+}
+
+func toPointZs(coords []coord) []goshp.PointZ {
+	points := make([]goshp.PointZ, len(coords))
+	for i, c := range coords {
+		points[i] = goshp.PointZ{X: c.X, Y: c.Y, Z: c.Z, M: c.M}
+	}
+	return points
+}
+
+func minMax(vals []float64) (min, max float64) {
+	if len(vals) == 0 {
+		return 0, 0
+	}
+	min, max = vals[0], vals[0]
+	for _, v := range vals[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return
+}
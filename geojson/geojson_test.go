@@ -0,0 +1,108 @@
+package geojson
+
+import (
+	"strings"
+	"testing"
+
+	goshp "github.com/davidrlarochelle/go-shp"
+)
+
+func TestDecodeSingleFeature(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"Point", `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"name":"a"}}
+		]}`},
+		{"PointZ", `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2,3]},"properties":{"name":"a"}}
+		]}`},
+		{"LineString", `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"n":1}}
+		]}`},
+		{"Polygon", `{"type":"FeatureCollection","features":[
+			{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[0,0],[0,1],[1,1],[1,0],[0,0]]]},"properties":{"n":1}}
+		]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shapes, fields, records, err := Decode(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if len(shapes) != 1 || len(records) != 1 {
+				t.Fatalf("got %d shapes, %d records; want 1 each", len(shapes), len(records))
+			}
+			if len(fields) != 1 {
+				t.Fatalf("got %d fields; want 1", len(fields))
+			}
+		})
+	}
+}
+
+func TestDateFieldRoundTrip(t *testing.T) {
+	in := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"d":"2024-01-15"}}
+	]}`
+	_, fields, records, err := Decode(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if fields[0].Fieldtype != 'D' {
+		t.Fatalf("field type = %c, want D", fields[0].Fieldtype)
+	}
+	if got := records[0][0]; got != "20240115" {
+		t.Fatalf("record value = %v, want 20240115", got)
+	}
+}
+
+func TestCollectFieldsIsDeterministic(t *testing.T) {
+	features := []feature{
+		{Properties: map[string]interface{}{"z": 1.0, "a": 2.0, "m": "x"}},
+	}
+
+	_, order := collectFields(features)
+	want := []string{"a", "m", "z"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestEncodeTrimsNulPaddedFieldNames(t *testing.T) {
+	f, err := goshp.NewField("id", 'N', 10, 0)
+	if err != nil {
+		t.Fatalf("NewField: %v", err)
+	}
+	if got := fieldName(f); got != "id" {
+		t.Fatalf("fieldName = %q, want %q", got, "id")
+	}
+}
+
+func TestEncodeDecodePolygonRoundTrip(t *testing.T) {
+	p := goshp.NewPolygonFromRings(
+		[][]goshp.Point{{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0}}},
+		[][][]goshp.Point{nil},
+	)
+	geom, err := shapeToGeometry(&p)
+	if err != nil {
+		t.Fatalf("shapeToGeometry: %v", err)
+	}
+	if geom.Type != "Polygon" {
+		t.Fatalf("geom.Type = %q, want Polygon", geom.Type)
+	}
+
+	shape, err := geometryToShape(geom)
+	if err != nil {
+		t.Fatalf("geometryToShape: %v", err)
+	}
+	if _, ok := shape.(*goshp.Polygon); !ok {
+		t.Fatalf("geometryToShape returned %T, want *goshp.Polygon", shape)
+	}
+}
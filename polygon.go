@@ -0,0 +1,385 @@
+package goshp
+
+// ringArea returns the shoelace sum over points, assumed to already form
+// a closed ring. Per the shapefile convention a negative result marks an
+// outer ring, a positive result a hole.
+func ringArea(points []Point) float64 {
+	var sum float64
+	for i := 0; i < len(points); i++ {
+		j := (i + 1) % len(points)
+		sum += points[i].X*points[j].Y - points[j].X*points[i].Y
+	}
+	return 0.5 * sum
+}
+
+func absFloat(a float64) float64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func (p Polygon) partPoints(i int) []Point {
+	start := p.Parts[i]
+	var end int32
+	if i == len(p.Parts)-1 {
+		end = p.NumPoints
+	} else {
+		end = p.Parts[i+1]
+	}
+	return p.Points[start:end]
+}
+
+// Rings splits the polygon's Parts into outer rings and the holes
+// belonging to each, using the shapefile convention: a ring whose
+// signed area is negative is an outer ring, positive is a hole, and
+// each hole is assigned to the smallest-area outer ring whose bounding
+// box contains the hole's first vertex.
+func (p Polygon) Rings() (outer [][]Point, holes [][][]Point) {
+	type ring struct {
+		points []Point
+		area   float64
+	}
+	rings := make([]ring, p.NumParts)
+	for i := 0; i < int(p.NumParts); i++ {
+		pts := p.partPoints(i)
+		rings[i] = ring{points: pts, area: ringArea(pts)}
+	}
+
+	var outers []ring
+	for _, r := range rings {
+		if r.area < 0 {
+			outers = append(outers, r)
+		}
+	}
+
+	// Real-world data sometimes violates the shapefile winding
+	// convention and stores every ring with positive area. Rather than
+	// classify all of them as orphan holes and drop the geometry
+	// entirely, fall back to treating every ring as its own outer ring;
+	// with no negative-area ring to anchor on, holes can't be told apart
+	// from outers, so none are assigned in this case.
+	noNegativeRings := len(outers) == 0
+	if noNegativeRings {
+		outers = rings
+	}
+
+	outer = make([][]Point, len(outers))
+	holes = make([][][]Point, len(outers))
+	for i, o := range outers {
+		outer[i] = o.points
+	}
+
+	if noNegativeRings {
+		return outer, holes
+	}
+
+	for _, r := range rings {
+		if r.area < 0 || len(r.points) == 0 {
+			continue
+		}
+		best := -1
+		first := r.points[0]
+		for i, o := range outers {
+			box := BBoxFromPoints(o.points)
+			if first.X < box.MinX || first.X > box.MaxX || first.Y < box.MinY || first.Y > box.MaxY {
+				continue
+			}
+			if best == -1 || absFloat(outers[i].area) < absFloat(outers[best].area) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			holes[best] = append(holes[best], r.points)
+		}
+	}
+
+	return outer, holes
+}
+
+// NewPolygonFromRings builds a Polygon from outer rings and the holes
+// associated with each (same indexing as Rings), concatenating them in
+// the correct winding order and filling Parts, NumParts, NumPoints, and
+// Box.
+func NewPolygonFromRings(outer [][]Point, holes [][][]Point) Polygon {
+	var points []Point
+	var parts []int32
+	for i, o := range outer {
+		parts = append(parts, int32(len(points)))
+		points = append(points, ensureOrientation(o, true)...)
+		if i < len(holes) {
+			for _, h := range holes[i] {
+				parts = append(parts, int32(len(points)))
+				points = append(points, ensureOrientation(h, false)...)
+			}
+		}
+	}
+
+	return Polygon{
+		Box:       BBoxFromPoints(points),
+		NumParts:  int32(len(parts)),
+		NumPoints: int32(len(points)),
+		Parts:     parts,
+		Points:    points,
+	}
+}
+
+// partPointsZ returns the PointZ values (zipping Points with the
+// parallel Zarray/Marray) of part i.
+func (p PolygonZ) partPointsZ(i int) []PointZ {
+	start := p.Parts[i]
+	var end int32
+	if i == len(p.Parts)-1 {
+		end = p.NumPoints
+	} else {
+		end = p.Parts[i+1]
+	}
+	pts := make([]PointZ, end-start)
+	for j := range pts {
+		pt := p.Points[int(start)+j]
+		pts[j] = PointZ{X: pt.X, Y: pt.Y, Z: p.Zarray[int(start)+j], M: p.Marray[int(start)+j]}
+	}
+	return pts
+}
+
+// Rings splits the PolygonZ's Parts into outer rings and their holes,
+// using the same convention as Polygon.Rings, carrying each vertex's Z/M
+// coordinates along.
+func (p PolygonZ) Rings() (outer [][]PointZ, holes [][][]PointZ) {
+	parts := make([][]PointZ, p.NumParts)
+	for i := range parts {
+		parts[i] = p.partPointsZ(i)
+	}
+	return splitRingsZM(parts, func(pts []PointZ) []Point {
+		plain := make([]Point, len(pts))
+		for i, pt := range pts {
+			plain[i] = Point{X: pt.X, Y: pt.Y}
+		}
+		return plain
+	})
+}
+
+// NewPolygonZFromRings is the PolygonZ counterpart to
+// NewPolygonFromRings.
+func NewPolygonZFromRings(outer [][]PointZ, holes [][][]PointZ) PolygonZ {
+	var points []Point
+	var zarr, marr []float64
+	var parts []int32
+	for i, o := range outer {
+		parts = append(parts, int32(len(points)))
+		points, zarr, marr = appendOrientedZ(points, zarr, marr, o, true)
+		if i < len(holes) {
+			for _, h := range holes[i] {
+				parts = append(parts, int32(len(points)))
+				points, zarr, marr = appendOrientedZ(points, zarr, marr, h, false)
+			}
+		}
+	}
+
+	zmin, zmax := minMaxFloat(zarr)
+	mmin, mmax := minMaxFloat(marr)
+	return PolygonZ{
+		Box:       BBoxFromPoints(points),
+		NumParts:  int32(len(parts)),
+		NumPoints: int32(len(points)),
+		Parts:     parts,
+		Points:    points,
+		Zmin:      zmin, Zmax: zmax, Zarray: zarr,
+		Mmin: mmin, Mmax: mmax, Marray: marr,
+	}
+}
+
+// partPointsM returns the PointM values (zipping Points with the
+// parallel Marray) of part i.
+func (p PolygonM) partPointsM(i int) []PointM {
+	start := p.Parts[i]
+	var end int32
+	if i == len(p.Parts)-1 {
+		end = p.NumPoints
+	} else {
+		end = p.Parts[i+1]
+	}
+	pts := make([]PointM, end-start)
+	for j := range pts {
+		pt := p.Points[int(start)+j]
+		pts[j] = PointM{X: pt.X, Y: pt.Y, M: p.Marray[int(start)+j]}
+	}
+	return pts
+}
+
+// Rings splits the PolygonM's Parts into outer rings and their holes,
+// using the same convention as Polygon.Rings, carrying each vertex's M
+// coordinate along.
+func (p PolygonM) Rings() (outer [][]PointM, holes [][][]PointM) {
+	parts := make([][]PointM, p.NumParts)
+	for i := range parts {
+		parts[i] = p.partPointsM(i)
+	}
+	return splitRingsZM(parts, func(pts []PointM) []Point {
+		plain := make([]Point, len(pts))
+		for i, pt := range pts {
+			plain[i] = Point{X: pt.X, Y: pt.Y}
+		}
+		return plain
+	})
+}
+
+// NewPolygonMFromRings is the PolygonM counterpart to
+// NewPolygonFromRings.
+func NewPolygonMFromRings(outer [][]PointM, holes [][][]PointM) PolygonM {
+	var points []Point
+	var marr []float64
+	var parts []int32
+	for i, o := range outer {
+		parts = append(parts, int32(len(points)))
+		points, marr = appendOrientedM(points, marr, o, true)
+		if i < len(holes) {
+			for _, h := range holes[i] {
+				parts = append(parts, int32(len(points)))
+				points, marr = appendOrientedM(points, marr, h, false)
+			}
+		}
+	}
+
+	mmin, mmax := minMaxFloat(marr)
+	return PolygonM{
+		Box:       BBoxFromPoints(points),
+		NumParts:  int32(len(parts)),
+		NumPoints: int32(len(points)),
+		Parts:     parts,
+		Points:    points,
+		Mmin:      mmin, Mmax: mmax, Marray: marr,
+	}
+}
+
+// splitRingsZM is the generic core of PolygonZ.Rings/PolygonM.Rings: it
+// classifies parts (already zipped into ring vertex slices of type T)
+// into outer rings and holes using plain(pts), which strips each ring
+// down to its X/Y for the area/bbox math that drives classification.
+func splitRingsZM[T any](parts [][]T, plain func([]T) []Point) (outer [][]T, holes [][][]T) {
+	type ring struct {
+		pts  []T
+		xy   []Point
+		area float64
+	}
+	rings := make([]ring, len(parts))
+	for i, pts := range parts {
+		xy := plain(pts)
+		rings[i] = ring{pts: pts, xy: xy, area: ringArea(xy)}
+	}
+
+	var outers []ring
+	for _, r := range rings {
+		if r.area < 0 {
+			outers = append(outers, r)
+		}
+	}
+	noNegativeRings := len(outers) == 0
+	if noNegativeRings {
+		outers = rings
+	}
+
+	outer = make([][]T, len(outers))
+	holes = make([][][]T, len(outers))
+	for i, o := range outers {
+		outer[i] = o.pts
+	}
+	if noNegativeRings {
+		return outer, holes
+	}
+
+	for _, r := range rings {
+		if r.area < 0 || len(r.pts) == 0 {
+			continue
+		}
+		best := -1
+		first := r.xy[0]
+		for i, o := range outers {
+			box := BBoxFromPoints(o.xy)
+			if first.X < box.MinX || first.X > box.MaxX || first.Y < box.MinY || first.Y > box.MaxY {
+				continue
+			}
+			if best == -1 || absFloat(outers[i].area) < absFloat(outers[best].area) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			holes[best] = append(holes[best], r.pts)
+		}
+	}
+
+	return outer, holes
+}
+
+// appendOrientedZ appends ring to points/z/m, reversing it first if
+// needed so its winding matches outer, mirroring ensureOrientation.
+func appendOrientedZ(points []Point, z, m []float64, ring []PointZ, outer bool) ([]Point, []float64, []float64) {
+	xy := make([]Point, len(ring))
+	for i, p := range ring {
+		xy[i] = Point{X: p.X, Y: p.Y}
+	}
+	area := ringArea(xy)
+	reverse := (outer && area >= 0) || (!outer && area <= 0)
+	for i := range ring {
+		p := ring[i]
+		if reverse {
+			p = ring[len(ring)-1-i]
+		}
+		points = append(points, Point{X: p.X, Y: p.Y})
+		z = append(z, p.Z)
+		m = append(m, p.M)
+	}
+	return points, z, m
+}
+
+// appendOrientedM appends ring to points/m, reversing it first if
+// needed so its winding matches outer, mirroring ensureOrientation.
+func appendOrientedM(points []Point, m []float64, ring []PointM, outer bool) ([]Point, []float64) {
+	xy := make([]Point, len(ring))
+	for i, p := range ring {
+		xy[i] = Point{X: p.X, Y: p.Y}
+	}
+	area := ringArea(xy)
+	reverse := (outer && area >= 0) || (!outer && area <= 0)
+	for i := range ring {
+		p := ring[i]
+		if reverse {
+			p = ring[len(ring)-1-i]
+		}
+		points = append(points, Point{X: p.X, Y: p.Y})
+		m = append(m, p.M)
+	}
+	return points, m
+}
+
+// minMaxFloat returns the min and max of vals, or 0,0 if vals is empty.
+func minMaxFloat(vals []float64) (min, max float64) {
+	if len(vals) == 0 {
+		return 0, 0
+	}
+	min, max = vals[0], vals[0]
+	for _, v := range vals[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return
+}
+
+// ensureOrientation returns ring re-ordered, if necessary, so that its
+// signed area is negative when outer is true (an outer ring) and
+// positive when outer is false (a hole).
+func ensureOrientation(ring []Point, outer bool) []Point {
+	area := ringArea(ring)
+	if (outer && area < 0) || (!outer && area > 0) {
+		return ring
+	}
+	reversed := make([]Point, len(ring))
+	for i, p := range ring {
+		reversed[len(ring)-1-i] = p
+	}
+	return reversed
+}
@@ -0,0 +1,81 @@
+package goshp
+
+import "testing"
+
+func square(x, y float64) []Point {
+	return rect(x, y, 1)
+}
+
+func rect(x, y, size float64) []Point {
+	return []Point{{x, y}, {x, y + size}, {x + size, y + size}, {x + size, y}}
+}
+
+// reverseRing flips a ring's winding, and so the sign of its signed area.
+func reverseRing(ring []Point) []Point {
+	reversed := make([]Point, len(ring))
+	for i, p := range ring {
+		reversed[len(ring)-1-i] = p
+	}
+	return reversed
+}
+
+func TestRingsSplitsOuterAndHole(t *testing.T) {
+	outer := rect(0, 0, 4)            // negative area: outer, 4x4
+	hole := reverseRing(square(1, 1)) // positive area: hole, fully inside outer's bbox
+
+	p := Polygon{
+		NumParts: 2, NumPoints: int32(len(outer) + len(hole)),
+		Parts:  []int32{0, int32(len(outer))},
+		Points: append(append([]Point{}, outer...), hole...),
+	}
+
+	outers, holes := p.Rings()
+	if len(outers) != 1 {
+		t.Fatalf("got %d outer rings, want 1", len(outers))
+	}
+	if len(holes[0]) != 1 {
+		t.Fatalf("got %d holes for the outer ring, want 1", len(holes[0]))
+	}
+}
+
+func TestRingsFallsBackWhenNoNegativeArea(t *testing.T) {
+	// Every ring wound the same (positive-area) way, violating the
+	// shapefile convention; Rings should treat each as its own outer
+	// ring rather than dropping the geometry.
+	a := reverseRing(square(0, 0))
+	b := reverseRing(square(5, 5))
+	p := Polygon{
+		NumParts: 2, NumPoints: int32(len(a) + len(b)),
+		Parts:  []int32{0, int32(len(a))},
+		Points: append(append([]Point{}, a...), b...),
+	}
+
+	outers, holes := p.Rings()
+	if len(outers) != 2 {
+		t.Fatalf("got %d outer rings, want 2", len(outers))
+	}
+	if len(holes[0]) != 0 || len(holes[1]) != 0 {
+		t.Fatalf("got holes %v, want none assigned", holes)
+	}
+}
+
+func TestNewPolygonFromRingsRoundTrip(t *testing.T) {
+	outer := rect(0, 0, 4)
+	hole := square(1, 1)
+
+	p := NewPolygonFromRings([][]Point{outer}, [][][]Point{{hole}})
+	outers, holes := p.Rings()
+	if len(outers) != 1 || len(holes[0]) != 1 {
+		t.Fatalf("got %d outers, %d holes on ring 0; want 1, 1", len(outers), len(holes[0]))
+	}
+}
+
+func TestNewPolygonFromRingsToleratesFewerHoleSlicesThanOuters(t *testing.T) {
+	outers := [][]Point{square(0, 0), square(5, 5)}
+	holes := [][][]Point{{square(0.25, 0.25)}} // no entry for the second outer ring
+
+	p := NewPolygonFromRings(outers, holes)
+	if p.NumParts != 3 {
+		t.Fatalf("NumParts = %d, want 3 (2 outers + 1 hole)", p.NumParts)
+	}
+}
@@ -0,0 +1,267 @@
+package goshp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reader reads shapes, and their associated DBF attributes, from a
+// shapefile (.shp/.shx/.dbf triple).
+type Reader struct {
+	GeometryType ShapeType
+	bbox         Box
+
+	shp       io.ReadSeeker
+	shpLength int64
+
+	dbf          io.ReaderAt
+	dbfFields    []Field
+	dbfRecordLen int32
+	dbfHeaderLen int32
+
+	crs CRS
+
+	closers []io.Closer
+
+	num   int32
+	shape Shape
+	err   error
+}
+
+// Open opens the .shp file at filename, and its .dbf sibling if present,
+// for reading.
+func Open(filename string) (*Reader, error) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	shp, err := os.Open(base + ".shp")
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newReader(shp)
+	if err != nil {
+		shp.Close()
+		return nil, err
+	}
+	r.closers = append(r.closers, shp)
+
+	if dbf, err := os.Open(base + ".dbf"); err == nil {
+		if err := r.openDbf(dbf); err != nil {
+			r.Close()
+			return nil, err
+		}
+		r.closers = append(r.closers, dbf)
+	}
+
+	if crs, err := readPrj(base + ".prj"); err == nil {
+		r.crs = crs
+	}
+
+	return r, nil
+}
+
+func newReader(shp io.ReadSeeker) (*Reader, error) {
+	var header [100]byte
+	if _, err := io.ReadFull(shp, header[:]); err != nil {
+		return nil, fmt.Errorf("goshp: reading shp header: %w", err)
+	}
+
+	fileLengthWords := int32(binary.BigEndian.Uint32(header[24:28]))
+	geomType := ShapeType(int32(binary.LittleEndian.Uint32(header[32:36])))
+	bbox := Box{
+		MinX: asFloat64(header[36:44]),
+		MinY: asFloat64(header[44:52]),
+		MaxX: asFloat64(header[52:60]),
+		MaxY: asFloat64(header[60:68]),
+	}
+
+	return &Reader{
+		GeometryType: geomType,
+		bbox:         bbox,
+		shp:          shp,
+		shpLength:    int64(fileLengthWords) * 2,
+	}, nil
+}
+
+func asFloat64(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (r *Reader) openDbf(dbf io.ReaderAt) error {
+	var header [32]byte
+	if _, err := io.NewSectionReader(dbf, 0, 32).Read(header[:]); err != nil {
+		return fmt.Errorf("goshp: reading dbf header: %w", err)
+	}
+
+	headerLen := int32(binary.LittleEndian.Uint16(header[8:10]))
+	recordLen := int32(binary.LittleEndian.Uint16(header[10:12]))
+
+	fieldSection := io.NewSectionReader(dbf, 32, int64(headerLen)-32)
+	var fields []Field
+	for {
+		var marker [1]byte
+		if _, err := fieldSection.Read(marker[:]); err != nil {
+			return fmt.Errorf("goshp: reading dbf fields: %w", err)
+		}
+		if marker[0] == 0x0D {
+			break
+		}
+		var rest [31]byte
+		if _, err := io.ReadFull(fieldSection, rest[:]); err != nil {
+			return fmt.Errorf("goshp: reading dbf fields: %w", err)
+		}
+		var f Field
+		f.Name[0] = marker[0]
+		copy(f.Name[1:], rest[0:10])
+		f.Fieldtype = rest[10]
+		copy(f.Addr[:], rest[11:15])
+		f.Size = rest[15]
+		f.Precision = rest[16]
+		copy(f.Padding[:], rest[17:31])
+		fields = append(fields, f)
+	}
+
+	r.dbf = dbf
+	r.dbfFields = fields
+	r.dbfHeaderLen = headerLen
+	r.dbfRecordLen = recordLen
+	return nil
+}
+
+// BBox returns the bounding box declared in the shp header.
+func (r *Reader) BBox() Box {
+	return r.bbox
+}
+
+// Fields returns the DBF field descriptors, in on-disk order.
+func (r *Reader) Fields() []Field {
+	return r.dbfFields
+}
+
+// Next advances to the next shape in the file, returning false once the
+// file is exhausted or an error occurred. Use Err to distinguish the two.
+func (r *Reader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	shapeType, ok := r.nextRecordType()
+	if !ok {
+		return false
+	}
+
+	shape, err := newShape(shapeType)
+	if err != nil {
+		r.err = err
+		return false
+	}
+	shape.read(r.shp)
+
+	r.num++
+	r.shape = shape
+	return true
+}
+
+// nextRecordType advances past the next record header and returns the
+// shape type that follows it, or false once the file is exhausted or an
+// error occurred.
+func (r *Reader) nextRecordType() (ShapeType, bool) {
+	pos, err := r.shp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		r.err = err
+		return 0, false
+	}
+	if pos >= r.shpLength {
+		return 0, false
+	}
+
+	var recordHeader [8]byte
+	if _, err := io.ReadFull(r.shp, recordHeader[:]); err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		return 0, false
+	}
+
+	var shapeTypeBuf [4]byte
+	if _, err := io.ReadFull(r.shp, shapeTypeBuf[:]); err != nil {
+		r.err = err
+		return 0, false
+	}
+	return ShapeType(int32(binary.LittleEndian.Uint32(shapeTypeBuf[:]))), true
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Shape returns the shape most recently produced by Next, along with its
+// 1-based record number.
+func (r *Reader) Shape() (int, Shape) {
+	return int(r.num), r.shape
+}
+
+// Attribute returns the DBF value of field n for the record most
+// recently produced by Next, as a trimmed string.
+func (r *Reader) Attribute(n int) string {
+	if r.dbf == nil || n < 0 || n >= len(r.dbfFields) {
+		return ""
+	}
+
+	offset := int64(r.dbfHeaderLen) + int64(r.num-1)*int64(r.dbfRecordLen) + 1 // +1 skips the deletion flag
+	for _, f := range r.dbfFields[:n] {
+		offset += int64(f.Size)
+	}
+
+	buf := make([]byte, r.dbfFields[n].Size)
+	if _, err := r.dbf.ReadAt(buf, offset); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(buf))
+}
+
+// Close releases the underlying file handles.
+func (r *Reader) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// newShape returns a zero-valued Shape for t, or an error if t has no
+// implementation in this package.
+func newShape(t ShapeType) (Shape, error) {
+	switch t {
+	case NULL:
+		return &Null{}, nil
+	case POINT:
+		return &Point{}, nil
+	case POLYLINE:
+		return &PolyLine{}, nil
+	case POLYGON:
+		return &Polygon{}, nil
+	case POINTZ:
+		return &PointZ{}, nil
+	case POLYLINEZ:
+		return &PolyLineZ{}, nil
+	case POLYGONZ:
+		return &PolygonZ{}, nil
+	case POINTM:
+		return &PointM{}, nil
+	case POLYLINEM:
+		return &PolyLineM{}, nil
+	case POLYGONM:
+		return &PolygonM{}, nil
+	default:
+		return nil, fmt.Errorf("goshp: unsupported shape type %d", t)
+	}
+}
@@ -0,0 +1,68 @@
+package goshp
+
+// ShapeIterator streams shapes from a Reader. By default each call to
+// Next allocates a fresh Shape, same as Reader.Next/Shape. Callers that
+// opt in via Reuse(true) instead get back the same Shape value with its
+// Parts/Points backing slices grown in place and overwritten, avoiding a
+// per-feature allocation on large PolyLine/Polygon datasets.
+//
+// A shape returned while reuse is enabled is only valid until the next
+// call to Next; retain it past that point by calling its Clone method
+// first.
+type ShapeIterator struct {
+	r     *Reader
+	reuse bool
+	shape Shape
+	num   int32
+	err   error
+}
+
+// Iterator returns a ShapeIterator over r.
+func (r *Reader) Iterator() *ShapeIterator {
+	return &ShapeIterator{r: r}
+}
+
+// Reuse enables or disables backing-slice reuse across calls to Next.
+func (it *ShapeIterator) Reuse(reuse bool) {
+	it.reuse = reuse
+}
+
+// Next advances to the next shape, returning false once the underlying
+// Reader is exhausted or an error occurred. Use Err to distinguish the
+// two.
+func (it *ShapeIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	shapeType, ok := it.r.nextRecordType()
+	if !ok {
+		it.err = it.r.err
+		return false
+	}
+
+	if it.reuse && it.shape != nil && it.shape.shapeType() == shapeType {
+		it.shape.read(it.r.shp)
+	} else {
+		shape, err := newShape(shapeType)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		shape.read(it.r.shp)
+		it.shape = shape
+	}
+
+	it.num++
+	return true
+}
+
+// Shape returns the shape most recently produced by Next.
+func (it *ShapeIterator) Shape() Shape {
+	return it.shape
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (it *ShapeIterator) Err() error {
+	return it.err
+}
@@ -2,6 +2,7 @@ package goshp
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
@@ -76,6 +77,7 @@ type Shape interface {
 
 	read(io.Reader)
 	write(io.Writer)
+	shapeType() ShapeType
 }
 
 // Shapefile NULL type
@@ -95,6 +97,16 @@ func (n *Null) write(file io.Writer) {
 	binary.Write(file, binary.LittleEndian, n)
 }
 
+func (n Null) shapeType() ShapeType {
+	return NULL
+}
+
+// Clone returns a copy of n. Null carries no backing slices, so this is
+// equivalent to a plain value copy.
+func (n Null) Clone() Null {
+	return n
+}
+
 // Shapefile Point type
 type Point struct {
 	X, Y float64
@@ -113,6 +125,16 @@ func (p *Point) write(file io.Writer) {
 	binary.Write(file, binary.LittleEndian, p)
 }
 
+func (p Point) shapeType() ShapeType {
+	return POINT
+}
+
+// Clone returns a copy of p. Point carries no backing slices, so this is
+// equivalent to a plain value copy.
+func (p Point) Clone() Point {
+	return p
+}
+
 // Shapefile PolyLine type
 type PolyLine struct {
 	Box
@@ -131,8 +153,8 @@ func (p *PolyLine) read(file io.Reader) {
 	binary.Read(file, binary.LittleEndian, &p.Box)
 	binary.Read(file, binary.LittleEndian, &p.NumParts)
 	binary.Read(file, binary.LittleEndian, &p.NumPoints)
-	p.Parts = make([]int32, p.NumParts)
-	p.Points = make([]Point, p.NumPoints)
+	p.Parts = growInt32Slice(p.Parts, int(p.NumParts))
+	p.Points = growPointSlice(p.Points, int(p.NumPoints))
 	binary.Read(file, binary.LittleEndian, &p.Parts)
 	binary.Read(file, binary.LittleEndian, &p.Points)
 }
@@ -145,6 +167,18 @@ func (p *PolyLine) write(file io.Writer) {
 	binary.Write(file, binary.LittleEndian, p.Points)
 }
 
+func (p PolyLine) shapeType() ShapeType {
+	return POLYLINE
+}
+
+// Clone returns a deep copy of p, safe to retain past the next read into
+// p's backing slices (see ShapeIterator.Reuse).
+func (p PolyLine) Clone() PolyLine {
+	p.Parts = append([]int32(nil), p.Parts...)
+	p.Points = append([]Point(nil), p.Points...)
+	return p
+}
+
 // Shapefile Polygon type
 // The Polygon structure is identical to the PolyLine structure
 type Polygon PolyLine
@@ -158,8 +192,8 @@ func (p *Polygon) read(file io.Reader) {
 	binary.Read(file, binary.LittleEndian, &p.Box)
 	binary.Read(file, binary.LittleEndian, &p.NumParts)
 	binary.Read(file, binary.LittleEndian, &p.NumPoints)
-	p.Parts = make([]int32, p.NumParts)
-	p.Points = make([]Point, p.NumPoints)
+	p.Parts = growInt32Slice(p.Parts, int(p.NumParts))
+	p.Points = growPointSlice(p.Points, int(p.NumPoints))
 	binary.Read(file, binary.LittleEndian, &p.Parts)
 	binary.Read(file, binary.LittleEndian, &p.Points)
 }
@@ -172,6 +206,44 @@ func (p *Polygon) write(file io.Writer) {
 	binary.Write(file, binary.LittleEndian, p.Points)
 }
 
+func (p Polygon) shapeType() ShapeType {
+	return POLYGON
+}
+
+// Clone returns a deep copy of p, safe to retain past the next read into
+// p's backing slices (see ShapeIterator.Reuse).
+func (p Polygon) Clone() Polygon {
+	return Polygon(PolyLine(p).Clone())
+}
+
+// growInt32Slice returns a slice of length n, reusing s's backing array
+// when it already has enough capacity and growing it by doubling
+// otherwise.
+func growInt32Slice(s []int32, n int) []int32 {
+	if cap(s) >= n {
+		return s[:n]
+	}
+	newCap := cap(s) * 2
+	if newCap < n {
+		newCap = n
+	}
+	return make([]int32, n, newCap)
+}
+
+// growPointSlice returns a slice of length n, reusing s's backing array
+// when it already has enough capacity and growing it by doubling
+// otherwise.
+func growPointSlice(s []Point, n int) []Point {
+	if cap(s) >= n {
+		return s[:n]
+	}
+	newCap := cap(s) * 2
+	if newCap < n {
+		newCap = n
+	}
+	return make([]Point, n, newCap)
+}
+
 // Field representation of a field object in the DBF file
 type Field struct {
 	Name      [11]byte
@@ -191,17 +263,22 @@ func (f Field) String() string {
 // Returns a StringField that can be used in SetFields to
 // initialize the DBF file.
 func StringField(name string, length uint8) Field {
-	// TODO: Error checking
+	if length > 254 {
+		length = 254
+	}
 	field := Field{Fieldtype: 'C', Size: length}
-	copy(field.Name[:], []byte(name))
+	copy(field.Name[:], clampFieldName(name))
 	return field
 }
 
 // Returns a NumberField that can be used in SetFields to
 // initialize the DBF file.
 func NumberField(name string, length uint8) Field {
+	if length > 18 {
+		length = 18
+	}
 	field := Field{Fieldtype: 'N', Size: length}
-	copy(field.Name[:], []byte(name))
+	copy(field.Name[:], clampFieldName(name))
 	return field
 }
 
@@ -209,8 +286,14 @@ func NumberField(name string, length uint8) Field {
 // initialize the DBF file. Used to store floating points
 // with precision in the DBF.
 func FloatField(name string, length uint8, precision uint8) Field {
+	if length > 20 {
+		length = 20
+	}
+	if precision >= length {
+		precision = length - 1
+	}
 	field := Field{Fieldtype: 'F', Size: length, Precision: precision}
-	copy(field.Name[:], []byte(name))
+	copy(field.Name[:], clampFieldName(name))
 	return field
 }
 
@@ -220,6 +303,69 @@ func FloatField(name string, length uint8, precision uint8) Field {
 // a StringField with length 8.
 func DateField(name string) Field {
 	field := Field{Fieldtype: 'D', Size: 8}
-	copy(field.Name[:], []byte(name))
+	copy(field.Name[:], clampFieldName(name))
 	return field
 }
+
+// Returns a LogicalField that can be used in SetFields to
+// initialize the DBF file. Stores one of T, F, Y, N, or ? (unset).
+func LogicalField(name string) Field {
+	field := Field{Fieldtype: 'L', Size: 1}
+	copy(field.Name[:], clampFieldName(name))
+	return field
+}
+
+// Returns a MemoField that can be used in SetFields to initialize the
+// DBF file. A memo field's value is a block number into a paired .dbt
+// file holding the actual variable-length text; see DBTWriter/DBTReader.
+func MemoField(name string) Field {
+	field := Field{Fieldtype: 'M', Size: 10}
+	copy(field.Name[:], clampFieldName(name))
+	return field
+}
+
+// clampFieldName truncates name to the 10 ASCII bytes a dBase III+
+// field name can hold, the same silent behavior StringField and its
+// siblings have always had. Callers that want an error on overlong or
+// non-ASCII names should use NewField instead.
+func clampFieldName(name string) []byte {
+	b := []byte(name)
+	if len(b) > 10 {
+		b = b[:10]
+	}
+	return b
+}
+
+// NewField builds a Field the same way the typed constructors
+// (StringField, NumberField, FloatField, DateField, ...) do, but
+// validates the name and size/precision against the dBase III+ limits
+// and returns an error instead of silently truncating into a corrupt
+// header.
+func NewField(name string, fieldtype byte, size, precision uint8) (Field, error) {
+	if len(name) == 0 || len(name) > 10 {
+		return Field{}, fmt.Errorf("goshp: field name %q must be 1-10 bytes", name)
+	}
+	for i := 0; i < len(name); i++ {
+		if name[i] > 127 {
+			return Field{}, fmt.Errorf("goshp: field name %q must be ASCII", name)
+		}
+	}
+
+	switch fieldtype {
+	case 'N':
+		if size > 18 {
+			return Field{}, fmt.Errorf("goshp: N field size %d exceeds the dBase III+ limit of 18", size)
+		}
+	case 'F':
+		if size > 20 {
+			return Field{}, fmt.Errorf("goshp: F field size %d exceeds the dBase III+ limit of 20", size)
+		}
+	}
+	if precision != 0 && precision >= size {
+		return Field{}, fmt.Errorf("goshp: precision %d must be less than size %d", precision, size)
+	}
+
+	field := Field{Fieldtype: fieldtype, Size: size, Precision: precision}
+	copy(field.Name[:], []byte(name))
+	return field, nil
+}
@@ -0,0 +1,257 @@
+package goshp
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// PointZ is a Point with an additional Z (elevation) and M (measure)
+// coordinate, per the shapefile PointZ record layout.
+type PointZ struct {
+	X, Y, Z, M float64
+}
+
+// Returns the bounding box of the PointZ feature. Z/M are not part of
+// Box, matching the 2D-only bounding boxes the rest of this package uses.
+func (p PointZ) BBox() Box {
+	return Box{p.X, p.Y, p.X, p.Y}
+}
+
+func (p *PointZ) read(file io.Reader) {
+	binary.Read(file, binary.LittleEndian, p)
+}
+
+func (p *PointZ) write(file io.Writer) {
+	binary.Write(file, binary.LittleEndian, p)
+}
+
+func (p PointZ) shapeType() ShapeType {
+	return POINTZ
+}
+
+// Clone returns a copy of p. PointZ carries no backing slices, so this is
+// equivalent to a plain value copy.
+func (p PointZ) Clone() PointZ {
+	return p
+}
+
+// PointM is a Point with an additional M (measure) coordinate, per the
+// shapefile PointM record layout.
+type PointM struct {
+	X, Y, M float64
+}
+
+// Returns the bounding box of the PointM feature.
+func (p PointM) BBox() Box {
+	return Box{p.X, p.Y, p.X, p.Y}
+}
+
+func (p *PointM) read(file io.Reader) {
+	binary.Read(file, binary.LittleEndian, p)
+}
+
+func (p *PointM) write(file io.Writer) {
+	binary.Write(file, binary.LittleEndian, p)
+}
+
+func (p PointM) shapeType() ShapeType {
+	return POINTM
+}
+
+// Clone returns a copy of p. PointM carries no backing slices, so this is
+// equivalent to a plain value copy.
+func (p PointM) Clone() PointM {
+	return p
+}
+
+// PolyLineZ is a PolyLine with a parallel Z array, and an M array (per
+// the shapefile spec M is written alongside Z for every Z-type record).
+type PolyLineZ struct {
+	Box
+	NumParts  int32
+	NumPoints int32
+	Parts     []int32
+	Points    []Point
+	Zmin      float64
+	Zmax      float64
+	Zarray    []float64
+	Mmin      float64
+	Mmax      float64
+	Marray    []float64
+}
+
+// Returns the bounding box of the PolyLineZ feature.
+func (p PolyLineZ) BBox() Box {
+	return BBoxFromPoints(p.Points)
+}
+
+func (p *PolyLineZ) read(file io.Reader) {
+	binary.Read(file, binary.LittleEndian, &p.Box)
+	binary.Read(file, binary.LittleEndian, &p.NumParts)
+	binary.Read(file, binary.LittleEndian, &p.NumPoints)
+	p.Parts = growInt32Slice(p.Parts, int(p.NumParts))
+	p.Points = growPointSlice(p.Points, int(p.NumPoints))
+	binary.Read(file, binary.LittleEndian, &p.Parts)
+	binary.Read(file, binary.LittleEndian, &p.Points)
+	binary.Read(file, binary.LittleEndian, &p.Zmin)
+	binary.Read(file, binary.LittleEndian, &p.Zmax)
+	p.Zarray = growFloat64Slice(p.Zarray, int(p.NumPoints))
+	binary.Read(file, binary.LittleEndian, &p.Zarray)
+	binary.Read(file, binary.LittleEndian, &p.Mmin)
+	binary.Read(file, binary.LittleEndian, &p.Mmax)
+	p.Marray = growFloat64Slice(p.Marray, int(p.NumPoints))
+	binary.Read(file, binary.LittleEndian, &p.Marray)
+}
+
+func (p *PolyLineZ) write(file io.Writer) {
+	binary.Write(file, binary.LittleEndian, p.Box)
+	binary.Write(file, binary.LittleEndian, p.NumParts)
+	binary.Write(file, binary.LittleEndian, p.NumPoints)
+	binary.Write(file, binary.LittleEndian, p.Parts)
+	binary.Write(file, binary.LittleEndian, p.Points)
+	binary.Write(file, binary.LittleEndian, p.Zmin)
+	binary.Write(file, binary.LittleEndian, p.Zmax)
+	binary.Write(file, binary.LittleEndian, p.Zarray)
+	binary.Write(file, binary.LittleEndian, p.Mmin)
+	binary.Write(file, binary.LittleEndian, p.Mmax)
+	binary.Write(file, binary.LittleEndian, p.Marray)
+}
+
+func (p PolyLineZ) shapeType() ShapeType {
+	return POLYLINEZ
+}
+
+// Clone returns a deep copy of p, safe to retain past the next read into
+// p's backing slices (see ShapeIterator.Reuse).
+func (p PolyLineZ) Clone() PolyLineZ {
+	p.Parts = append([]int32(nil), p.Parts...)
+	p.Points = append([]Point(nil), p.Points...)
+	p.Zarray = append([]float64(nil), p.Zarray...)
+	p.Marray = append([]float64(nil), p.Marray...)
+	return p
+}
+
+// PolygonZ is a Polygon with a parallel Z array, and an M array. The
+// structure is identical to PolyLineZ, matching the relationship between
+// Polygon and PolyLine.
+type PolygonZ PolyLineZ
+
+// Returns the bounding box of the PolygonZ feature.
+func (p PolygonZ) BBox() Box {
+	return BBoxFromPoints(p.Points)
+}
+
+func (p *PolygonZ) read(file io.Reader) {
+	(*PolyLineZ)(p).read(file)
+}
+
+func (p *PolygonZ) write(file io.Writer) {
+	(*PolyLineZ)(p).write(file)
+}
+
+func (p PolygonZ) shapeType() ShapeType {
+	return POLYGONZ
+}
+
+// Clone returns a deep copy of p, safe to retain past the next read into
+// p's backing slices (see ShapeIterator.Reuse).
+func (p PolygonZ) Clone() PolygonZ {
+	return PolygonZ(PolyLineZ(p).Clone())
+}
+
+// PolyLineM is a PolyLine with a parallel M array.
+type PolyLineM struct {
+	Box
+	NumParts  int32
+	NumPoints int32
+	Parts     []int32
+	Points    []Point
+	Mmin      float64
+	Mmax      float64
+	Marray    []float64
+}
+
+// Returns the bounding box of the PolyLineM feature.
+func (p PolyLineM) BBox() Box {
+	return BBoxFromPoints(p.Points)
+}
+
+func (p *PolyLineM) read(file io.Reader) {
+	binary.Read(file, binary.LittleEndian, &p.Box)
+	binary.Read(file, binary.LittleEndian, &p.NumParts)
+	binary.Read(file, binary.LittleEndian, &p.NumPoints)
+	p.Parts = growInt32Slice(p.Parts, int(p.NumParts))
+	p.Points = growPointSlice(p.Points, int(p.NumPoints))
+	binary.Read(file, binary.LittleEndian, &p.Parts)
+	binary.Read(file, binary.LittleEndian, &p.Points)
+	binary.Read(file, binary.LittleEndian, &p.Mmin)
+	binary.Read(file, binary.LittleEndian, &p.Mmax)
+	p.Marray = growFloat64Slice(p.Marray, int(p.NumPoints))
+	binary.Read(file, binary.LittleEndian, &p.Marray)
+}
+
+func (p *PolyLineM) write(file io.Writer) {
+	binary.Write(file, binary.LittleEndian, p.Box)
+	binary.Write(file, binary.LittleEndian, p.NumParts)
+	binary.Write(file, binary.LittleEndian, p.NumPoints)
+	binary.Write(file, binary.LittleEndian, p.Parts)
+	binary.Write(file, binary.LittleEndian, p.Points)
+	binary.Write(file, binary.LittleEndian, p.Mmin)
+	binary.Write(file, binary.LittleEndian, p.Mmax)
+	binary.Write(file, binary.LittleEndian, p.Marray)
+}
+
+func (p PolyLineM) shapeType() ShapeType {
+	return POLYLINEM
+}
+
+// Clone returns a deep copy of p, safe to retain past the next read into
+// p's backing slices (see ShapeIterator.Reuse).
+func (p PolyLineM) Clone() PolyLineM {
+	p.Parts = append([]int32(nil), p.Parts...)
+	p.Points = append([]Point(nil), p.Points...)
+	p.Marray = append([]float64(nil), p.Marray...)
+	return p
+}
+
+// PolygonM is a Polygon with a parallel M array. The structure is
+// identical to PolyLineM, matching the relationship between Polygon and
+// PolyLine.
+type PolygonM PolyLineM
+
+// Returns the bounding box of the PolygonM feature.
+func (p PolygonM) BBox() Box {
+	return BBoxFromPoints(p.Points)
+}
+
+func (p *PolygonM) read(file io.Reader) {
+	(*PolyLineM)(p).read(file)
+}
+
+func (p *PolygonM) write(file io.Writer) {
+	(*PolyLineM)(p).write(file)
+}
+
+func (p PolygonM) shapeType() ShapeType {
+	return POLYGONM
+}
+
+// Clone returns a deep copy of p, safe to retain past the next read into
+// p's backing slices (see ShapeIterator.Reuse).
+func (p PolygonM) Clone() PolygonM {
+	return PolygonM(PolyLineM(p).Clone())
+}
+
+// growFloat64Slice returns a slice of length n, reusing s's backing array
+// when it already has enough capacity and growing it by doubling
+// otherwise.
+func growFloat64Slice(s []float64, n int) []float64 {
+	if cap(s) >= n {
+		return s[:n]
+	}
+	newCap := cap(s) * 2
+	if newCap < n {
+		newCap = n
+	}
+	return make([]float64, n, newCap)
+}
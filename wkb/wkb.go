@@ -0,0 +1,626 @@
+// Package wkb converts between goshp.Shape values and OGC Well-Known
+// Binary, including the PostGIS EWKB extension that prefixes an SRID.
+//
+// Both 2D and Z/M-extended shapes are supported: goshp.PointZ/PolyLineZ/
+// PolygonZ round-trip through the EWKB Z+M flag bits, and
+// goshp.PointM/PolyLineM/PolygonM round-trip through the M-only flag. The
+// Z/M flag bits (0x80000000, 0x40000000) follow the same PostGIS EWKB
+// convention as the SRID flag below, rather than the ISO SQL/MM
+// 1000/2000/3000 type-code offset scheme.
+//
+// goshp.Null has no point, line or polygon WKB equivalent, so it
+// round-trips through the empty GeometryCollection (type code 7).
+package wkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	goshp "github.com/davidrlarochelle/go-shp"
+)
+
+// Byte order markers used in the WKB header.
+const (
+	xdr = 0 // big-endian
+	ndr = 1 // little-endian
+)
+
+// Geometry type codes from the OGC WKB spec.
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// sridFlag is OR'd into the geometry type to mark the EWKB variant that
+// carries an SRID immediately after the type code. zFlag/mFlag mark the
+// presence of a Z and/or M coordinate on every point, per the same EWKB
+// convention.
+const (
+	zFlag    = 0x80000000
+	mFlag    = 0x40000000
+	sridFlag = 0x20000000
+)
+
+var errUnsupportedShape = errors.New("wkb: unsupported shape type")
+var errTruncated = errors.New("wkb: truncated input")
+
+// AsWKB encodes s as little-endian (NDR) Well-Known Binary.
+func AsWKB(s goshp.Shape) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := encode(buf, s, false, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// AsEWKB encodes s as NDR Well-Known Binary extended with srid, the form
+// PostGIS expects when inserting directly into a geometry column.
+func AsEWKB(s goshp.Shape, srid uint32) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := encode(buf, s, true, srid); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// AsEWKBForCRS is a convenience wrapper around AsEWKB that takes the SRID
+// from crs.EPSG, for callers that already carry a goshp.CRS (e.g. read
+// from a .prj sidecar via Reader.CRS).
+func AsEWKBForCRS(s goshp.Shape, crs goshp.CRS) ([]byte, error) {
+	return AsEWKB(s, uint32(crs.EPSG))
+}
+
+// FromWKB decodes b, which may be plain WKB or the SRID-extended EWKB
+// variant, into a Shape. Any SRID present is consumed but discarded.
+func FromWKB(b []byte) (goshp.Shape, error) {
+	r := bytes.NewReader(b)
+	s, _, err := decode(r)
+	return s, err
+}
+
+func encode(buf *bytes.Buffer, s goshp.Shape, withSRID bool, srid uint32) error {
+	switch g := s.(type) {
+	case *goshp.Null:
+		writeHeader(buf, wkbGeometryCollection, false, false, withSRID, srid)
+		binary.Write(buf, binary.LittleEndian, uint32(0))
+		return nil
+	case *goshp.Point:
+		writeHeader(buf, wkbPoint, false, false, withSRID, srid)
+		writePoint(buf, g.X, g.Y, 0, 0, false, false)
+		return nil
+	case *goshp.PointZ:
+		writeHeader(buf, wkbPoint, true, true, withSRID, srid)
+		writePoint(buf, g.X, g.Y, g.Z, g.M, true, true)
+		return nil
+	case *goshp.PointM:
+		writeHeader(buf, wkbPoint, false, true, withSRID, srid)
+		writePoint(buf, g.X, g.Y, 0, g.M, false, true)
+		return nil
+	case *goshp.PolyLine:
+		return encodePolyLine(buf, g.Parts, g.NumParts, g.NumPoints, g.Points, nil, nil, false, false, withSRID, srid)
+	case *goshp.PolyLineZ:
+		return encodePolyLine(buf, g.Parts, g.NumParts, g.NumPoints, g.Points, g.Zarray, g.Marray, true, true, withSRID, srid)
+	case *goshp.PolyLineM:
+		return encodePolyLine(buf, g.Parts, g.NumParts, g.NumPoints, g.Points, nil, g.Marray, false, true, withSRID, srid)
+	case *goshp.Polygon:
+		return encodePolygon(buf, *g, withSRID, srid)
+	case *goshp.PolygonZ:
+		return encodePolygonZ(buf, *g, withSRID, srid)
+	case *goshp.PolygonM:
+		return encodePolygonM(buf, *g, withSRID, srid)
+	default:
+		return fmt.Errorf("wkb: %w: %T", errUnsupportedShape, s)
+	}
+}
+
+func writeHeader(buf *bytes.Buffer, geomType uint32, withZ, withM, withSRID bool, srid uint32) {
+	buf.WriteByte(ndr)
+	if withZ {
+		geomType |= zFlag
+	}
+	if withM {
+		geomType |= mFlag
+	}
+	if withSRID {
+		geomType |= sridFlag
+	}
+	binary.Write(buf, binary.LittleEndian, geomType)
+	if withSRID {
+		binary.Write(buf, binary.LittleEndian, srid)
+	}
+}
+
+func writePoint(buf *bytes.Buffer, x, y, z, m float64, withZ, withM bool) {
+	binary.Write(buf, binary.LittleEndian, x)
+	binary.Write(buf, binary.LittleEndian, y)
+	if withZ {
+		binary.Write(buf, binary.LittleEndian, z)
+	}
+	if withM {
+		binary.Write(buf, binary.LittleEndian, m)
+	}
+}
+
+func partRange(parts []int32, numPoints int32, i int) (start, end int32) {
+	start = parts[i]
+	if i == len(parts)-1 {
+		end = numPoints
+	} else {
+		end = parts[i+1]
+	}
+	return
+}
+
+// encodePolyLine writes the LineString/MultiLineString form shared by
+// PolyLine, PolyLineZ, and PolyLineM; z and m may be nil when the shape
+// doesn't carry that coordinate.
+func encodePolyLine(buf *bytes.Buffer, parts []int32, numParts, numPoints int32, points []goshp.Point, z, m []float64, withZ, withM, withSRID bool, srid uint32) error {
+	if numParts <= 1 {
+		writeHeader(buf, wkbLineString, withZ, withM, withSRID, srid)
+		writeLineString(buf, points, z, m, withZ, withM)
+		return nil
+	}
+	writeHeader(buf, wkbMultiLineString, withZ, withM, withSRID, srid)
+	binary.Write(buf, binary.LittleEndian, uint32(numParts))
+	for i := 0; i < int(numParts); i++ {
+		start, end := partRange(parts, numPoints, i)
+		writeHeader(buf, wkbLineString, withZ, withM, false, 0)
+		writeLineString(buf, points[start:end], slice(z, start, end), slice(m, start, end), withZ, withM)
+	}
+	return nil
+}
+
+// slice returns s[start:end], or nil if s itself is nil.
+func slice(s []float64, start, end int32) []float64 {
+	if s == nil {
+		return nil
+	}
+	return s[start:end]
+}
+
+func writeLineString(buf *bytes.Buffer, points []goshp.Point, z, m []float64, withZ, withM bool) {
+	binary.Write(buf, binary.LittleEndian, uint32(len(points)))
+	for i, pt := range points {
+		writePoint(buf, pt.X, pt.Y, at(z, i), at(m, i), withZ, withM)
+	}
+}
+
+// at returns s[i], or 0 if s is nil.
+func at(s []float64, i int) float64 {
+	if s == nil {
+		return 0
+	}
+	return s[i]
+}
+
+func writeRing(buf *bytes.Buffer, points []goshp.Point, z, m []float64, withZ, withM bool) {
+	writeLineString(buf, points, z, m, withZ, withM)
+}
+
+func encodePolygon(buf *bytes.Buffer, p goshp.Polygon, withSRID bool, srid uint32) error {
+	outers, holes := p.Rings()
+
+	if len(outers) == 1 {
+		writeHeader(buf, wkbPolygon, false, false, withSRID, srid)
+		writePolygonRings(buf, outers[0], holes[0], nil, nil, nil, nil, false, false)
+		return nil
+	}
+
+	writeHeader(buf, wkbMultiPolygon, false, false, withSRID, srid)
+	binary.Write(buf, binary.LittleEndian, uint32(len(outers)))
+	for i, o := range outers {
+		writeHeader(buf, wkbPolygon, false, false, false, 0)
+		writePolygonRings(buf, o, holes[i], nil, nil, nil, nil, false, false)
+	}
+	return nil
+}
+
+func writePolygonRings(buf *bytes.Buffer, outer []goshp.Point, holes [][]goshp.Point, outerZ, outerM []float64, holesZ, holesM [][]float64, withZ, withM bool) {
+	binary.Write(buf, binary.LittleEndian, uint32(1+len(holes)))
+	writeRing(buf, outer, outerZ, outerM, withZ, withM)
+	for i, h := range holes {
+		writeRing(buf, h, zmAt(holesZ, i), zmAt(holesM, i), withZ, withM)
+	}
+}
+
+// zmAt returns s[i], or nil if s is nil (the shape carries no Z or M).
+func zmAt(s [][]float64, i int) []float64 {
+	if s == nil {
+		return nil
+	}
+	return s[i]
+}
+
+// ringArea2D is the shoelace sum used to classify a ring's winding,
+// ignoring any Z/M coordinates, mirroring goshp's own ringArea.
+func ringArea2D(points []goshp.Point) float64 {
+	var sum float64
+	for i := 0; i < len(points); i++ {
+		j := (i + 1) % len(points)
+		sum += points[i].X*points[j].Y - points[j].X*points[i].Y
+	}
+	return 0.5 * sum
+}
+
+// zmRing bundles a ring's points with its parallel Z/M coordinates (Z/M
+// may be nil) for the PolygonZ/PolygonM encoders, which can't reuse
+// goshp.Polygon.Rings since that only carries 2D points.
+type zmRing struct {
+	points []goshp.Point
+	z, m   []float64
+	area   float64
+}
+
+// splitZMRings classifies parts into outer rings and the holes belonging
+// to each, using the same convention as goshp.Polygon.Rings.
+func splitZMRings(parts []int32, numPoints int32, points []goshp.Point, z, m []float64) (outers []zmRing, holes [][]zmRing) {
+	rings := make([]zmRing, len(parts))
+	for i := range parts {
+		start, end := partRange(parts, numPoints, i)
+		rings[i] = zmRing{
+			points: points[start:end],
+			z:      slice(z, start, end),
+			m:      slice(m, start, end),
+			area:   ringArea2D(points[start:end]),
+		}
+	}
+
+	var outerRings []zmRing
+	for _, r := range rings {
+		if r.area < 0 {
+			outerRings = append(outerRings, r)
+		}
+	}
+	noNegativeRings := len(outerRings) == 0
+	if noNegativeRings {
+		outerRings = rings
+	}
+
+	outers = outerRings
+	holes = make([][]zmRing, len(outers))
+	if noNegativeRings {
+		return outers, holes
+	}
+
+	for _, r := range rings {
+		if r.area < 0 || len(r.points) == 0 {
+			continue
+		}
+		best := -1
+		first := r.points[0]
+		for i, o := range outers {
+			box := goshp.BBoxFromPoints(o.points)
+			if first.X < box.MinX || first.X > box.MaxX || first.Y < box.MinY || first.Y > box.MaxY {
+				continue
+			}
+			if best == -1 || absFloat(outers[i].area) < absFloat(outers[best].area) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			holes[best] = append(holes[best], r)
+		}
+	}
+
+	return outers, holes
+}
+
+func absFloat(a float64) float64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func encodePolygonZ(buf *bytes.Buffer, p goshp.PolygonZ, withSRID bool, srid uint32) error {
+	outers, holes := splitZMRings(p.Parts, p.NumPoints, p.Points, p.Zarray, p.Marray)
+	writeZMPolygonRings(buf, outers, holes, true, true, withSRID, srid)
+	return nil
+}
+
+func encodePolygonM(buf *bytes.Buffer, p goshp.PolygonM, withSRID bool, srid uint32) error {
+	outers, holes := splitZMRings(p.Parts, p.NumPoints, p.Points, nil, p.Marray)
+	writeZMPolygonRings(buf, outers, holes, false, true, withSRID, srid)
+	return nil
+}
+
+func writeZMPolygonRings(buf *bytes.Buffer, outers []zmRing, holes [][]zmRing, withZ, withM bool, withSRID bool, srid uint32) {
+	if len(outers) == 1 {
+		writeHeader(buf, wkbPolygon, withZ, withM, withSRID, srid)
+		writeZMRingSet(buf, outers[0], holes[0], withZ, withM)
+		return
+	}
+
+	writeHeader(buf, wkbMultiPolygon, withZ, withM, withSRID, srid)
+	binary.Write(buf, binary.LittleEndian, uint32(len(outers)))
+	for i, o := range outers {
+		writeHeader(buf, wkbPolygon, withZ, withM, false, 0)
+		writeZMRingSet(buf, o, holes[i], withZ, withM)
+	}
+}
+
+func writeZMRingSet(buf *bytes.Buffer, outer zmRing, holes []zmRing, withZ, withM bool) {
+	binary.Write(buf, binary.LittleEndian, uint32(1+len(holes)))
+	writeRing(buf, outer.points, outer.z, outer.m, withZ, withM)
+	for _, h := range holes {
+		writeRing(buf, h.points, h.z, h.m, withZ, withM)
+	}
+}
+
+func decode(r *bytes.Reader) (goshp.Shape, uint32, error) {
+	order, rawType, srid, err := readHeader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	hasZ := rawType&zFlag != 0
+	hasM := rawType&mFlag != 0
+	rawType &^= zFlag | mFlag
+
+	switch rawType {
+	case wkbGeometryCollection:
+		var n uint32
+		if err := binary.Read(r, order, &n); err != nil {
+			return nil, 0, errTruncated
+		}
+		if n != 0 {
+			return nil, 0, fmt.Errorf("wkb: %w: non-empty GeometryCollection", errUnsupportedShape)
+		}
+		return &goshp.Null{}, srid, nil
+	case wkbPoint:
+		p, err := readRawPoint(r, order, hasZ, hasM)
+		if err != nil {
+			return nil, 0, err
+		}
+		return pointShape(p, hasZ, hasM), srid, nil
+	case wkbLineString:
+		pts, err := readRawLineString(r, order, hasZ, hasM)
+		if err != nil {
+			return nil, 0, err
+		}
+		return polyLineShape([][]rawPoint{pts}, hasZ, hasM), srid, nil
+	case wkbMultiLineString:
+		var n uint32
+		if err := binary.Read(r, order, &n); err != nil {
+			return nil, 0, errTruncated
+		}
+		parts := make([][]rawPoint, n)
+		for i := range parts {
+			subOrder, _, err := readSubHeader(r)
+			if err != nil {
+				return nil, 0, err
+			}
+			pts, err := readRawLineString(r, subOrder, hasZ, hasM)
+			if err != nil {
+				return nil, 0, err
+			}
+			parts[i] = pts
+		}
+		return polyLineShape(parts, hasZ, hasM), srid, nil
+	case wkbPolygon:
+		rings, err := readRawPolygonRings(r, order, hasZ, hasM)
+		if err != nil {
+			return nil, 0, err
+		}
+		return polygonShape(rings, hasZ, hasM), srid, nil
+	case wkbMultiPolygon:
+		var n uint32
+		if err := binary.Read(r, order, &n); err != nil {
+			return nil, 0, errTruncated
+		}
+		var allRings [][]rawPoint
+		for i := uint32(0); i < n; i++ {
+			subOrder, _, err := readSubHeader(r)
+			if err != nil {
+				return nil, 0, err
+			}
+			rings, err := readRawPolygonRings(r, subOrder, hasZ, hasM)
+			if err != nil {
+				return nil, 0, err
+			}
+			allRings = append(allRings, rings...)
+		}
+		return polygonShape(allRings, hasZ, hasM), srid, nil
+	default:
+		return nil, 0, fmt.Errorf("wkb: %w: type code %d", errUnsupportedShape, rawType)
+	}
+}
+
+// readHeader reads the byte-order marker, geometry type (with any SRID
+// flag consumed), and the SRID itself when present.
+func readHeader(r *bytes.Reader) (binary.ByteOrder, uint32, uint32, error) {
+	byteOrderByte, err := r.ReadByte()
+	if err != nil {
+		return nil, 0, 0, errTruncated
+	}
+	order := byteOrder(byteOrderByte)
+
+	var rawType uint32
+	if err := binary.Read(r, order, &rawType); err != nil {
+		return nil, 0, 0, errTruncated
+	}
+	var srid uint32
+	if rawType&sridFlag != 0 {
+		rawType &^= sridFlag
+		if err := binary.Read(r, order, &srid); err != nil {
+			return nil, 0, 0, errTruncated
+		}
+	}
+	return order, rawType, srid, nil
+}
+
+func byteOrder(b byte) binary.ByteOrder {
+	if b == xdr {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// readSubHeader consumes the byte-order+type header embedded before each
+// element of a multi-geometry and returns the sub-geometry's own byte
+// order, which may differ from its parent's; the type itself is not
+// validated since it is implied by the parent geometry.
+func readSubHeader(r *bytes.Reader) (binary.ByteOrder, uint32, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, 0, errTruncated
+	}
+	order := byteOrder(b)
+	var t uint32
+	if err := binary.Read(r, order, &t); err != nil {
+		return nil, 0, errTruncated
+	}
+	return order, t, nil
+}
+
+// rawPoint carries every coordinate WKB can encode for a point; Z and/or
+// M are left at zero when the geometry doesn't actually carry them.
+type rawPoint struct {
+	X, Y, Z, M float64
+}
+
+func readRawPoint(r io.Reader, order binary.ByteOrder, hasZ, hasM bool) (rawPoint, error) {
+	var p rawPoint
+	if err := binary.Read(r, order, &p.X); err != nil {
+		return p, errTruncated
+	}
+	if err := binary.Read(r, order, &p.Y); err != nil {
+		return p, errTruncated
+	}
+	if hasZ {
+		if err := binary.Read(r, order, &p.Z); err != nil {
+			return p, errTruncated
+		}
+	}
+	if hasM {
+		if err := binary.Read(r, order, &p.M); err != nil {
+			return p, errTruncated
+		}
+	}
+	return p, nil
+}
+
+func readRawLineString(r io.Reader, order binary.ByteOrder, hasZ, hasM bool) ([]rawPoint, error) {
+	var n uint32
+	if err := binary.Read(r, order, &n); err != nil {
+		return nil, errTruncated
+	}
+	pts := make([]rawPoint, n)
+	for i := range pts {
+		p, err := readRawPoint(r, order, hasZ, hasM)
+		if err != nil {
+			return nil, err
+		}
+		pts[i] = p
+	}
+	return pts, nil
+}
+
+func readRawPolygonRings(r io.Reader, order binary.ByteOrder, hasZ, hasM bool) ([][]rawPoint, error) {
+	var n uint32
+	if err := binary.Read(r, order, &n); err != nil {
+		return nil, errTruncated
+	}
+	rings := make([][]rawPoint, n)
+	for i := range rings {
+		pts, err := readRawLineString(r, order, hasZ, hasM)
+		if err != nil {
+			return nil, err
+		}
+		rings[i] = pts
+	}
+	return rings, nil
+}
+
+func pointShape(p rawPoint, hasZ, hasM bool) goshp.Shape {
+	switch {
+	case hasZ:
+		return &goshp.PointZ{X: p.X, Y: p.Y, Z: p.Z, M: p.M}
+	case hasM:
+		return &goshp.PointM{X: p.X, Y: p.Y, M: p.M}
+	default:
+		return &goshp.Point{X: p.X, Y: p.Y}
+	}
+}
+
+// flattenParts concatenates parts into a single Points slice plus the
+// per-part Z/M arrays and starting offsets shared by every PolyLine*/
+// Polygon* variant's on-disk layout.
+func flattenParts(parts [][]rawPoint) (points []goshp.Point, z, m []float64, offsets []int32) {
+	offsets = make([]int32, len(parts))
+	for i, part := range parts {
+		offsets[i] = int32(len(points))
+		for _, p := range part {
+			points = append(points, goshp.Point{X: p.X, Y: p.Y})
+			z = append(z, p.Z)
+			m = append(m, p.M)
+		}
+	}
+	return
+}
+
+func polyLineShape(parts [][]rawPoint, hasZ, hasM bool) goshp.Shape {
+	points, z, m, offsets := flattenParts(parts)
+	switch {
+	case hasZ:
+		zmin, zmax := minMax(z)
+		mmin, mmax := minMax(m)
+		return &goshp.PolyLineZ{
+			Box: goshp.BBoxFromPoints(points), NumParts: int32(len(parts)), NumPoints: int32(len(points)),
+			Parts: offsets, Points: points,
+			Zmin: zmin, Zmax: zmax, Zarray: z,
+			Mmin: mmin, Mmax: mmax, Marray: m,
+		}
+	case hasM:
+		mmin, mmax := minMax(m)
+		return &goshp.PolyLineM{
+			Box: goshp.BBoxFromPoints(points), NumParts: int32(len(parts)), NumPoints: int32(len(points)),
+			Parts: offsets, Points: points,
+			Mmin: mmin, Mmax: mmax, Marray: m,
+		}
+	default:
+		return &goshp.PolyLine{
+			Box: goshp.BBoxFromPoints(points), NumParts: int32(len(parts)), NumPoints: int32(len(points)),
+			Parts: offsets, Points: points,
+		}
+	}
+}
+
+func polygonShape(rings [][]rawPoint, hasZ, hasM bool) goshp.Shape {
+	switch pl := polyLineShape(rings, hasZ, hasM).(type) {
+	case *goshp.PolyLineZ:
+		pg := goshp.PolygonZ(*pl)
+		return &pg
+	case *goshp.PolyLineM:
+		pg := goshp.PolygonM(*pl)
+		return &pg
+	case *goshp.PolyLine:
+		pg := goshp.Polygon(*pl)
+		return &pg
+	default:
+		panic("wkb: unreachable")
+	}
+}
+
+func minMax(vals []float64) (min, max float64) {
+	if len(vals) == 0 {
+		return 0, 0
+	}
+	min, max = vals[0], vals[0]
+	for _, v := range vals[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return
+}
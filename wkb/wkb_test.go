@@ -0,0 +1,108 @@
+package wkb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	goshp "github.com/davidrlarochelle/go-shp"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape goshp.Shape
+	}{
+		{"Point", &goshp.Point{X: 1, Y: 2}},
+		{"PointZ", &goshp.PointZ{X: 1, Y: 2, Z: 3, M: 4}},
+		{"PointM", &goshp.PointM{X: 1, Y: 2, M: 4}},
+		{"PolyLine single part", &goshp.PolyLine{
+			NumParts: 1, NumPoints: 2,
+			Parts:  []int32{0},
+			Points: []goshp.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+		}},
+		{"PolyLine multi part", &goshp.PolyLine{
+			NumParts: 2, NumPoints: 4,
+			Parts:  []int32{0, 2},
+			Points: []goshp.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}},
+		}},
+		{"PolyLineZ", &goshp.PolyLineZ{
+			NumParts: 1, NumPoints: 2,
+			Parts:  []int32{0},
+			Points: []goshp.Point{{X: 0, Y: 0}, {X: 1, Y: 1}},
+			Zarray: []float64{10, 20}, Marray: []float64{100, 200},
+		}},
+		{"Polygon single outer ring", &goshp.Polygon{
+			NumParts: 1, NumPoints: 4,
+			Parts: []int32{0},
+			Points: []goshp.Point{
+				{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 0},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := AsWKB(tt.shape)
+			if err != nil {
+				t.Fatalf("AsWKB: %v", err)
+			}
+			got, err := FromWKB(b)
+			if err != nil {
+				t.Fatalf("FromWKB: %v", err)
+			}
+
+			back, err := AsWKB(got)
+			if err != nil {
+				t.Fatalf("AsWKB(round-tripped): %v", err)
+			}
+			if !bytes.Equal(back, b) {
+				t.Fatalf("round trip mismatch:\n  original: % x\n  got:      % x", b, back)
+			}
+		})
+	}
+}
+
+func TestAsEWKBIncludesSRID(t *testing.T) {
+	p := &goshp.Point{X: 1, Y: 2}
+	b, err := AsEWKB(p, 4326)
+	if err != nil {
+		t.Fatalf("AsEWKB: %v", err)
+	}
+
+	got, srid, err := decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if srid != 4326 {
+		t.Errorf("srid = %d, want 4326", srid)
+	}
+	pt, ok := got.(*goshp.Point)
+	if !ok || pt.X != 1 || pt.Y != 2 {
+		t.Errorf("decoded shape = %#v, want Point{1,2}", got)
+	}
+}
+
+// TestDecodeMultiLineStringMixedByteOrder ensures each sub-geometry's own
+// byte-order flag drives how its points are read, not the parent's.
+func TestDecodeMultiLineStringMixedByteOrder(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteByte(ndr) // parent byte order
+	binary.Write(&buf, binary.LittleEndian, uint32(wkbMultiLineString))
+	binary.Write(&buf, binary.LittleEndian, uint32(1)) // one sub-geometry
+
+	buf.WriteByte(xdr) // sub-geometry is big-endian, unlike the parent
+	binary.Write(&buf, binary.BigEndian, uint32(wkbLineString))
+	binary.Write(&buf, binary.BigEndian, uint32(1)) // one point
+	binary.Write(&buf, binary.BigEndian, 1.5)
+	binary.Write(&buf, binary.BigEndian, 2.5)
+
+	shape, err := FromWKB(buf.Bytes())
+	if err != nil {
+		t.Fatalf("FromWKB: %v", err)
+	}
+	pl, ok := shape.(*goshp.PolyLine)
+	if !ok || len(pl.Points) != 1 || pl.Points[0].X != 1.5 || pl.Points[0].Y != 2.5 {
+		t.Fatalf("decoded shape = %#v, want a single point at (1.5, 2.5)", shape)
+	}
+}
@@ -0,0 +1,268 @@
+package goshp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	fileCode    = 9994
+	fileVersion = 1000
+)
+
+// Writer writes shapes, and their associated DBF attributes, to a
+// shapefile (.shp/.shx/.dbf triple).
+type Writer struct {
+	GeometryType ShapeType
+
+	shp *os.File
+	shx *os.File
+	dbf *os.File
+
+	fields []Field
+	bbox   Box
+	crs    CRS
+
+	shpOffset int64 // bytes written to .shp, not counting the header
+	num       int32
+
+	err error
+}
+
+// Create creates a new shapefile (and the matching .shx) at filename for
+// writing shapes of the given type.
+func Create(filename string, t ShapeType) (*Writer, error) {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	shp, err := os.Create(base + ".shp")
+	if err != nil {
+		return nil, err
+	}
+	shx, err := os.Create(base + ".shx")
+	if err != nil {
+		shp.Close()
+		return nil, err
+	}
+
+	if _, err := shp.Write(make([]byte, 100)); err != nil {
+		return nil, err
+	}
+	if _, err := shx.Write(make([]byte, 100)); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		GeometryType: t,
+		shp:          shp,
+		shx:          shx,
+	}, nil
+}
+
+// SetFields declares the DBF field layout and creates the .dbf file. It
+// must be called before the first call to WriteAttribute.
+func (w *Writer) SetFields(fields []Field) error {
+	base := strings.TrimSuffix(w.shp.Name(), filepath.Ext(w.shp.Name()))
+	dbf, err := os.Create(base + ".dbf")
+	if err != nil {
+		return err
+	}
+	w.fields = fields
+	w.dbf = dbf
+	return nil
+}
+
+// SetCRS attaches a coordinate reference system to the shapefile; it is
+// written out as a .prj sidecar when Close is called.
+func (w *Writer) SetCRS(crs CRS) {
+	w.crs = crs
+}
+
+// Write appends shape to the .shp/.shx files and returns its 1-based
+// record number.
+func (w *Writer) Write(shape Shape) (int32, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, int32(w.GeometryType))
+	shape.write(buf)
+	content := buf.Bytes()
+
+	w.num++
+	var recordHeader [8]byte
+	binary.BigEndian.PutUint32(recordHeader[0:4], uint32(w.num))
+	binary.BigEndian.PutUint32(recordHeader[4:8], uint32(len(content)/2))
+
+	if _, err := w.shp.Write(recordHeader[:]); err != nil {
+		w.err = err
+		return 0, err
+	}
+	if _, err := w.shp.Write(content); err != nil {
+		w.err = err
+		return 0, err
+	}
+
+	var shxRecord [8]byte
+	binary.BigEndian.PutUint32(shxRecord[0:4], uint32(50+w.shpOffset/2))
+	binary.BigEndian.PutUint32(shxRecord[4:8], uint32(len(content)/2))
+	if _, err := w.shx.Write(shxRecord[:]); err != nil {
+		w.err = err
+		return 0, err
+	}
+
+	w.shpOffset += int64(8 + len(content))
+	if w.num == 1 {
+		w.bbox = shape.BBox()
+	} else {
+		w.bbox.Extend(shape.BBox())
+	}
+
+	return w.num, nil
+}
+
+// WriteAttribute sets the value of field for the 0-based row row. Rows
+// must be written in order starting from 0.
+func (w *Writer) WriteAttribute(row, field int, value interface{}) error {
+	if w.dbf == nil {
+		return errors.New("goshp: SetFields must be called before WriteAttribute")
+	}
+	if field < 0 || field >= len(w.fields) {
+		return fmt.Errorf("goshp: field index %d out of range", field)
+	}
+
+	f := w.fields[field]
+	s := fmt.Sprintf("%v", value)
+	if len(s) > int(f.Size) {
+		s = s[:f.Size]
+	}
+	padded := make([]byte, f.Size)
+	if f.Fieldtype == 'N' || f.Fieldtype == 'F' {
+		copy(padded[len(padded)-len(s):], []byte(s))
+		for i := 0; i < len(padded)-len(s); i++ {
+			padded[i] = ' '
+		}
+	} else {
+		copy(padded, []byte(s))
+		for i := len(s); i < len(padded); i++ {
+			padded[i] = ' '
+		}
+	}
+
+	recordLen := int64(1)
+	for _, fd := range w.fields {
+		recordLen += int64(fd.Size)
+	}
+	headerLen := int64(32 + 32*len(w.fields) + 1)
+	recordStart := headerLen + int64(row)*recordLen
+	if _, err := w.dbf.WriteAt([]byte{' '}, recordStart); err != nil {
+		return err
+	}
+	offset := recordStart + 1
+	for _, fd := range w.fields[:field] {
+		offset += int64(fd.Size)
+	}
+
+	if _, err := w.dbf.WriteAt(padded, offset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close finalizes the .shp/.shx headers, the .dbf header if present, the
+// .prj sidecar if a CRS was set, and closes the underlying files.
+func (w *Writer) Close() error {
+	if err := w.writeShpHeader(w.shp, w.shpOffset); err != nil {
+		return err
+	}
+	if err := w.writeShpHeader(w.shx, int64(8*w.num)); err != nil {
+		return err
+	}
+	if w.dbf != nil {
+		if err := w.writeDbfHeader(); err != nil {
+			return err
+		}
+		if _, err := w.dbf.Write([]byte{0x1A}); err != nil {
+			return err
+		}
+	}
+	if w.crs.WKT != "" || w.crs.EPSG != 0 {
+		if err := writePrj(prjFilename(w.shp.Name()), w.crs); err != nil {
+			return err
+		}
+	}
+
+	if err := w.shp.Close(); err != nil {
+		return err
+	}
+	if err := w.shx.Close(); err != nil {
+		return err
+	}
+	if w.dbf != nil {
+		return w.dbf.Close()
+	}
+	return nil
+}
+
+func (w *Writer) writeShpHeader(f *os.File, contentLen int64) error {
+	var header [100]byte
+	binary.BigEndian.PutUint32(header[0:4], fileCode)
+	binary.BigEndian.PutUint32(header[24:28], uint32((100+contentLen)/2))
+	binary.LittleEndian.PutUint32(header[28:32], fileVersion)
+	binary.LittleEndian.PutUint32(header[32:36], uint32(w.GeometryType))
+	putFloat64(header[36:44], w.bbox.MinX)
+	putFloat64(header[44:52], w.bbox.MinY)
+	putFloat64(header[52:60], w.bbox.MaxX)
+	putFloat64(header[60:68], w.bbox.MaxY)
+
+	if _, err := f.WriteAt(header[:], 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w *Writer) writeDbfHeader() error {
+	recordLen := 1
+	for _, f := range w.fields {
+		recordLen += int(f.Size)
+	}
+	headerLen := 32 + 32*len(w.fields) + 1
+
+	var header [32]byte
+	header[0] = 0x03
+	binary.LittleEndian.PutUint32(header[4:8], uint32(w.num))
+	binary.LittleEndian.PutUint16(header[8:10], uint16(headerLen))
+	binary.LittleEndian.PutUint16(header[10:12], uint16(recordLen))
+	if _, err := w.dbf.WriteAt(header[:], 0); err != nil {
+		return err
+	}
+
+	offset := int64(32)
+	for _, f := range w.fields {
+		var raw [32]byte
+		copy(raw[0:11], f.Name[:])
+		raw[11] = f.Fieldtype
+		copy(raw[12:16], f.Addr[:])
+		raw[16] = f.Size
+		raw[17] = f.Precision
+		copy(raw[18:32], f.Padding[:])
+		if _, err := w.dbf.WriteAt(raw[:], offset); err != nil {
+			return err
+		}
+		offset += 32
+	}
+	if _, err := w.dbf.WriteAt([]byte{0x0D}, offset); err != nil {
+		return err
+	}
+	return nil
+}
+
+func putFloat64(b []byte, v float64) {
+	binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+}